@@ -0,0 +1,192 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gofr.dev/pkg/gofr"
+)
+
+// Adopt registers an existing compose project as a deployment so it can be
+// managed with the normal lifecycle commands
+func (s *Service) Adopt(c *gofr.Context) (interface{}, error) {
+	dir := getArg(c)
+	if dir == "" {
+		return nil, fmt.Errorf("usage: opensourcer adopt <dir> [--software <slug>] [--force]")
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	composePath := findComposeFile(dir)
+	project, err := loadComposeFile(composePath)
+	if err != nil {
+		return nil, fmt.Errorf("no compose project found in %s", dir)
+	}
+
+	if err := checkDockerAvailable(); err != nil {
+		return nil, fmt.Errorf("docker is required to adopt a compose project: %w", err)
+	}
+
+	// Match the project to a catalog entry
+	software := getFlagValue(c, "software")
+	if software == "" {
+		software, err = s.matchCatalogEntry(project)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		catalogCompose, err := loadComposeFile(s.getComposePath(software))
+		if err != nil {
+			return nil, fmt.Errorf("software '%s' not found in catalog", software)
+		}
+		if imageMatchScore(project, catalogCompose) == 0 && c.Param("force") != "true" {
+			return nil, fmt.Errorf("none of the images in %s match catalog entry '%s'. Use --force to adopt anyway", dir, software)
+		}
+	}
+
+	detail, err := s.getCatalogDetail(software)
+	if err != nil {
+		return nil, err
+	}
+
+	deployments, err := s.readDeployments()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deployments {
+		if d.Software == software {
+			return nil, fmt.Errorf("a deployment of '%s' already exists", software)
+		}
+		if d.Directory == dir {
+			return nil, fmt.Errorf("%s is already managed as '%s'", dir, d.Software)
+		}
+	}
+
+	deployment, err := s.registerProject(dir, software, detail, true)
+	if err != nil {
+		return nil, err
+	}
+	inputs, status := deployment.Inputs, deployment.Status
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("\n✅ Adopted %s as '%s' (%s)\n\n", dir, software, detail.Name))
+	output.WriteString(fmt.Sprintf("  Status: %s\n", status))
+	if deployment.Port > 0 {
+		output.WriteString(fmt.Sprintf("  URL: http://localhost:%d\n", deployment.Port))
+	}
+	if len(inputs) > 0 {
+		output.WriteString(fmt.Sprintf("  Inputs recovered from .env: %s\n", strings.Join(sortedKeys(inputs), ", ")))
+	}
+	output.WriteString("\n  The directory is left in place when the deployment is destroyed.\n")
+
+	output.WriteString("\nUseful commands:\n")
+	output.WriteString(fmt.Sprintf("  opensourcer logs %s    - View logs\n", software))
+	output.WriteString(fmt.Sprintf("  opensourcer stop %s    - Stop deployment\n", software))
+	output.WriteString(fmt.Sprintf("  opensourcer destroy %s - Remove deployment\n", software))
+
+	return output.String(), nil
+}
+
+// registerProject records the compose project in dir as a deployment of
+// software, recovering its inputs from .env and its status from Docker
+func (s *Service) registerProject(dir, software string, detail *CatalogDetail, adopted bool) (*LocalDeployment, error) {
+	composePath := findComposeFile(dir)
+	project, err := loadComposeFile(composePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Recover the catalog inputs from the project's .env
+	inputs := make(map[string]string)
+	if envVars, err := parseEnvFile(filepath.Join(dir, ".env")); err == nil {
+		for key := range detail.Inputs {
+			if value, ok := envVars[inputEnvKey(key)]; ok && value != "" {
+				inputs[key] = value
+			}
+		}
+	}
+
+	composeContent, err := os.ReadFile(composePath)
+	if err != nil {
+		return nil, err
+	}
+
+	port := findExposedPort(string(composeContent))
+	if port == 0 {
+		port = project.firstHostPort()
+	}
+
+	status, err := projectStatus(dir)
+	if err != nil {
+		return nil, err
+	}
+	if status != "running" {
+		status = "stopped"
+	}
+
+	deployment := LocalDeployment{
+		ID:        uuid.New().String(),
+		Software:  software,
+		Target:    "local",
+		Status:    status,
+		Directory: dir,
+		Port:      port,
+		Inputs:    inputs,
+		Adopted:   adopted,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := s.addDeployment(deployment); err != nil {
+		return nil, err
+	}
+
+	return &deployment, nil
+}
+
+// matchCatalogEntry finds the catalog entry whose images best match the project
+func (s *Service) matchCatalogEntry(project *ComposeFile) (string, error) {
+	items, err := s.listCatalogItems()
+	if err != nil {
+		return "", fmt.Errorf("catalog not found. Run 'opensourcer update' first")
+	}
+
+	best, bestScore := "", 0
+	for _, slug := range items {
+		catalogCompose, err := loadComposeFile(s.getComposePath(slug))
+		if err != nil {
+			continue
+		}
+		if score := imageMatchScore(project, catalogCompose); score > bestScore {
+			best, bestScore = slug, score
+		}
+	}
+
+	if best == "" {
+		return "", fmt.Errorf("could not match the project to a catalog entry. Use --software <slug>")
+	}
+	return best, nil
+}
+
+// imageMatchScore counts the catalog images that the project also runs, ignoring tags and registries
+func imageMatchScore(project, catalog *ComposeFile) int {
+	projectImages := make(map[string]bool)
+	for _, svc := range project.Services {
+		projectImages[imageName(svc.Image)] = true
+	}
+
+	score := 0
+	for _, svc := range catalog.Services {
+		if svc.Image != "" && projectImages[imageName(svc.Image)] {
+			score++
+		}
+	}
+	return score
+}
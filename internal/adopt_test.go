@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestImageMatchScore(t *testing.T) {
+	catalog := parseTestCompose(t, `
+services:
+  web:
+    image: gitea/gitea:1.21
+  db:
+    image: postgres:16
+  build-only:
+    build: .
+`)
+
+	tests := []struct {
+		name    string
+		project string
+		want    int
+	}{
+		{
+			name:    "same images",
+			project: "services:\n  app:\n    image: gitea/gitea:1.21\n  database:\n    image: postgres:16\n",
+			want:    2,
+		},
+		{
+			name:    "other tags and registries",
+			project: "services:\n  app:\n    image: ghcr.io/gitea/gitea:latest\n  database:\n    image: docker.io/library/postgres@sha256:abc\n",
+			want:    2,
+		},
+		{
+			name:    "one shared image",
+			project: "services:\n  app:\n    image: forgejo/forgejo:1\n  database:\n    image: postgres:15\n",
+			want:    1,
+		},
+		{
+			name:    "no shared images",
+			project: "services:\n  app:\n    image: nginx:1\n",
+			want:    0,
+		},
+		{
+			name:    "build without image",
+			project: "services:\n  app:\n    build: .\n",
+			want:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := imageMatchScore(parseTestCompose(t, tt.project), catalog); got != tt.want {
+				t.Errorf("imageMatchScore = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchCatalogEntry(t *testing.T) {
+	s := newTestService(t)
+	writeFile(t, filepath.Join(s.catalogPath, "gitea", "docker-compose.yaml"), "services:\n  web:\n    image: gitea/gitea:1\n  db:\n    image: postgres:16\n")
+	writeFile(t, filepath.Join(s.catalogPath, "wiki", "docker-compose.yaml"), "services:\n  web:\n    image: requarks/wiki:2\n  db:\n    image: postgres:16\n")
+	writeFile(t, filepath.Join(s.catalogPath, "_template", "docker-compose.yaml"), "services:\n  web:\n    image: gitea/gitea:1\n")
+
+	tests := []struct {
+		name    string
+		project string
+		want    string
+		wantErr bool
+	}{
+		{name: "best match wins", project: "services:\n  a:\n    image: gitea/gitea:1.22\n  b:\n    image: postgres:15\n", want: "gitea"},
+		{name: "shared database only", project: "services:\n  a:\n    image: requarks/wiki:2\n  b:\n    image: postgres:16\n", want: "wiki"},
+		{name: "no match", project: "services:\n  a:\n    image: nginx:1\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.matchCatalogEntry(parseTestCompose(t, tt.project))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("matchCatalogEntry: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("matched %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterProjectRecoversInputs(t *testing.T) {
+	setupFakeDocker(t, "")
+	s := newTestService(t)
+
+	dir := filepath.Join(t.TempDir(), "gitea")
+	writeFile(t, filepath.Join(dir, "docker-compose.yaml"), "services:\n  web:\n    image: gitea/gitea:1\n    ports:\n      - \"3000:3000\"\n")
+	writeFile(t, filepath.Join(dir, ".env"), "# generated\nDOMAIN=git.example.com\nADMIN_PASSWORD=secret\nSITE_TITLE=\nUNRELATED=1\n")
+	writeFile(t, filepath.Join(dir, ".fake-state"), "exited\nrunning\n")
+
+	detail := &CatalogDetail{Inputs: map[string]InputConfig{
+		"domain":         {},
+		"admin_password": {Type: "password"},
+		"site_title":     {},
+		"admin_email":    {},
+	}}
+
+	d, err := s.registerProject(dir, "gitea", detail, true)
+	if err != nil {
+		t.Fatalf("registerProject: %v", err)
+	}
+
+	tests := []struct {
+		key  string
+		want string
+		ok   bool
+	}{
+		{key: "domain", want: "git.example.com", ok: true},
+		{key: "admin_password", want: "secret", ok: true},
+		{key: "site_title"},
+		{key: "admin_email"},
+	}
+	for _, tt := range tests {
+		got, ok := d.Inputs[tt.key]
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("input %s = %q (present %v), want %q (present %v)", tt.key, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if d.Status != "running" || d.Port != 3000 || !d.Adopted {
+		t.Errorf("deployment = %+v, want running on port 3000 and adopted", d)
+	}
+	if found, err := s.findDeployment("gitea"); err != nil || found == nil || found.ID != d.ID {
+		t.Errorf("deployment not recorded: %+v, %v", found, err)
+	}
+}
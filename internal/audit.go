@@ -0,0 +1,259 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gofr.dev/pkg/gofr"
+)
+
+const auditPolicyFileName = "policy.json"
+
+// Audit rules checked against compose files
+const (
+	rulePrivileged   = "privileged"
+	ruleHostNetwork  = "host_network"
+	ruleDockerSocket = "docker_socket"
+	ruleHostBind     = "host_bind"
+	ruleLatestTag    = "latest_tag"
+	ruleCapAdd       = "cap_add"
+)
+
+var auditRules = []string{rulePrivileged, ruleHostNetwork, ruleDockerSocket, ruleHostBind, ruleLatestTag, ruleCapAdd}
+
+// Policy actions for a rule
+const (
+	policyIgnore = "ignore"
+	policyWarn   = "warn"
+	policyBlock  = "block"
+)
+
+// auditPolicy decides what happens when a rule matches. Rules default to
+// warn; entries under Software override them for one catalog entry.
+type auditPolicy struct {
+	Rules    map[string]string            `json:"rules"`
+	Software map[string]map[string]string `json:"software"`
+}
+
+// auditFinding is one risky setting found in a compose file
+type auditFinding struct {
+	Rule    string
+	Service string
+	Message string
+	Action  string
+}
+
+func (s *Service) auditPolicyPath() string {
+	return filepath.Join(s.configPath, auditPolicyFileName)
+}
+
+// loadAuditPolicy reads ~/.opensourcer/policy.json, or returns the default
+// policy if there is none
+func (s *Service) loadAuditPolicy() (*auditPolicy, error) {
+	policy := &auditPolicy{}
+
+	data, err := os.ReadFile(s.auditPolicyPath())
+	if os.IsNotExist(err) {
+		return policy, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", auditPolicyFileName, err)
+	}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", auditPolicyFileName, err)
+	}
+
+	check := func(rules map[string]string) error {
+		for rule, action := range rules {
+			// Rule names are matched exactly when applied, so "Privileged" would never match
+			if !slices.Contains(auditRules, rule) {
+				return fmt.Errorf("invalid %s: unknown rule '%s' (rules are %s)", auditPolicyFileName, rule, strings.Join(auditRules, ", "))
+			}
+			if action != policyIgnore && action != policyWarn && action != policyBlock {
+				return fmt.Errorf("invalid %s: action for '%s' must be ignore, warn or block", auditPolicyFileName, rule)
+			}
+		}
+		return nil
+	}
+	if err := check(policy.Rules); err != nil {
+		return nil, err
+	}
+	for _, rules := range policy.Software {
+		if err := check(rules); err != nil {
+			return nil, err
+		}
+	}
+
+	return policy, nil
+}
+
+// action returns what to do when rule matches in software
+func (p *auditPolicy) action(software, rule string) string {
+	if action, ok := p.Software[software][rule]; ok {
+		return action
+	}
+	if action, ok := p.Rules[rule]; ok {
+		return action
+	}
+	return policyWarn
+}
+
+// auditCompose flags risky settings in the services of a compose file. Bind
+// mounts are allowed inside dir, where the compose file is deployed; vars
+// are used to resolve image tags. Services in skip are not checked.
+func auditCompose(compose *ComposeFile, dir string, vars map[string]string, skip map[string]bool) []auditFinding {
+	var findings []auditFinding
+	add := func(rule, service, format string, args ...interface{}) {
+		findings = append(findings, auditFinding{Rule: rule, Service: service, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, name := range compose.serviceNames() {
+		if skip[name] {
+			continue
+		}
+		svc := compose.Services[name]
+
+		if svc.Privileged {
+			add(rulePrivileged, name, "runs privileged, with full access to the host")
+		}
+		if interpolateCompose(svc.NetworkMode, vars) == "host" {
+			add(ruleHostNetwork, name, "uses the host network")
+		}
+		for _, capability := range svc.CapAdd {
+			add(ruleCapAdd, name, "adds the %s capability", interpolateCompose(capability, vars))
+		}
+
+		if image := interpolateCompose(svc.Image, vars); image != "" && imageTag(image) == "latest" {
+			add(ruleLatestTag, name, "uses the latest tag of %s, so the version can change on every pull", imageName(image))
+		}
+
+		for _, v := range svc.Volumes {
+			// Interpolate first, so a variable with a host path default is classified by that path
+			source := interpolateCompose(v.Source, vars)
+			if !isBindMount(source) {
+				continue
+			}
+			source = resolveBindSource(dir, source)
+			if filepath.Base(source) == "docker.sock" {
+				add(ruleDockerSocket, name, "mounts the Docker socket (%s), which gives it control of the host", source)
+				continue
+			}
+			if rel, err := filepath.Rel(dir, source); err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+				mode := "read-write"
+				if v.ReadOnly {
+					mode = "read-only"
+				}
+				add(ruleHostBind, name, "mounts host path %s %s", source, mode)
+			}
+		}
+	}
+
+	return findings
+}
+
+// apply sets the policy action of each finding and drops ignored ones
+func (p *auditPolicy) apply(software string, findings []auditFinding) []auditFinding {
+	var kept []auditFinding
+	for _, f := range findings {
+		f.Action = p.action(software, f.Rule)
+		if f.Action != policyIgnore {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// auditDeploymentDir audits the compose file in a deployment directory with
+// the values from its .env, skipping services replaced by external instances
+func (s *Service) auditDeploymentDir(software, dir string, externals []string) ([]auditFinding, error) {
+	policy, err := s.loadAuditPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	vars, err := parseEnvFile(filepath.Join(dir, ".env"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .env file: %w", err)
+	}
+	compose, err := loadComposeFileWithVars(findComposeFile(dir), vars)
+	if err != nil {
+		return nil, err
+	}
+
+	dropped := make(map[string]bool)
+	for _, name := range externals {
+		dropped[name] = true
+	}
+	return policy.apply(software, auditCompose(compose, dir, vars, dropped)), nil
+}
+
+// blockedFindingsError returns an error listing the findings the policy blocks, or nil
+func (s *Service) blockedFindingsError(findings []auditFinding) error {
+	var blocked []string
+	for _, f := range findings {
+		if f.Action == policyBlock {
+			blocked = append(blocked, fmt.Sprintf("  - %s: %s (%s)", f.Service, f.Message, f.Rule))
+		}
+	}
+	if len(blocked) == 0 {
+		return nil
+	}
+	return fmt.Errorf("blocked by security policy:\n%s\n\nChange the rules in %s to allow it", strings.Join(blocked, "\n"), s.auditPolicyPath())
+}
+
+// writeFindings writes findings as a list, marking blocked ones
+func writeFindings(output *strings.Builder, indent string, findings []auditFinding) {
+	for _, f := range findings {
+		output.WriteString(fmt.Sprintf("%s- [%s] %s: %s\n", indent, f.Action, f.Service, f.Message))
+	}
+}
+
+// Audit checks a catalog entry's compose file for risky settings and reports
+// what the security policy does about each
+func (s *Service) Audit(c *gofr.Context) (interface{}, error) {
+	software := getArg(c)
+	if software == "" {
+		return nil, fmt.Errorf("usage: opensourcer audit <software>")
+	}
+
+	detail, err := s.getCatalogDetail(software)
+	if err != nil {
+		return nil, err
+	}
+
+	compose, err := loadComposeFile(s.getComposePath(software))
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := s.loadAuditPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	findings := policy.apply(software, auditCompose(compose, filepath.Dir(s.getComposePath(software)), nil, nil))
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("\n🛡️  Security audit for %s\n", detail.Name))
+	output.WriteString(strings.Repeat("-", 60) + "\n\n")
+
+	if len(findings) == 0 {
+		output.WriteString("  No issues found.\n")
+		return output.String(), nil
+	}
+
+	writeFindings(&output, "  ", findings)
+
+	if s.blockedFindingsError(findings) != nil {
+		output.WriteString("\nDeploying is blocked by the security policy.\n")
+	} else {
+		output.WriteString("\nDeploying is allowed; the findings are shown as warnings.\n")
+	}
+	output.WriteString(fmt.Sprintf("Policy: %s\n", s.auditPolicyPath()))
+
+	return output.String(), nil
+}
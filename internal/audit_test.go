@@ -0,0 +1,250 @@
+package internal
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func parseTestCompose(t *testing.T, content string) *ComposeFile {
+	t.Helper()
+	var compose ComposeFile
+	if err := yaml.Unmarshal([]byte(content), &compose); err != nil {
+		t.Fatalf("parsing compose file: %v", err)
+	}
+	return &compose
+}
+
+func TestAuditCompose(t *testing.T) {
+	dir := "/home/user/.opensourcer/deployments/app"
+
+	tests := []struct {
+		name    string
+		compose string
+		vars    map[string]string
+		skip    map[string]bool
+		want    []string
+	}{
+		{
+			name: "clean service",
+			compose: `
+services:
+  web:
+    image: nginx:1.25
+    volumes:
+      - data:/data
+      - ./config:/etc/nginx/conf.d:ro
+volumes:
+  data:
+`,
+		},
+		{
+			name: "privileged, host network and capabilities",
+			compose: `
+services:
+  web:
+    image: nginx:1.25
+    privileged: true
+    network_mode: host
+    cap_add: [NET_ADMIN]
+`,
+			want: []string{rulePrivileged, ruleHostNetwork, ruleCapAdd},
+		},
+		{
+			name: "latest and untagged images",
+			compose: `
+services:
+  a:
+    image: nginx:latest
+  b:
+    image: nginx
+  c:
+    image: nginx:${TAG:-latest}
+  d:
+    image: nginx:${TAG}
+`,
+			vars: map[string]string{"TAG": "1.25"},
+			want: []string{ruleLatestTag, ruleLatestTag},
+		},
+		{
+			name: "docker socket",
+			compose: `
+services:
+  a:
+    image: traefik:3
+    volumes:
+      - /var/run/docker.sock:/var/run/docker.sock:ro
+`,
+			want: []string{ruleDockerSocket},
+		},
+		{
+			name: "docker socket behind a variable default",
+			compose: `
+services:
+  a:
+    image: traefik:3
+    volumes:
+      - ${SOCK:-/var/run/docker.sock}:/var/run/docker.sock
+`,
+			want: []string{ruleDockerSocket},
+		},
+		{
+			name: "host root behind a variable default",
+			compose: `
+services:
+  a:
+    image: alpine:3
+    volumes:
+      - ${DATA:-/}:/host
+`,
+			want: []string{ruleHostBind},
+		},
+		{
+			name: "host path from a variable",
+			compose: `
+services:
+  a:
+    image: alpine:3
+    volumes:
+      - ${DATA}:/data
+`,
+			vars: map[string]string{"DATA": "/etc"},
+			want: []string{ruleHostBind},
+		},
+		{
+			name: "relative path leaving the deployment directory",
+			compose: `
+services:
+  a:
+    image: alpine:3
+    volumes:
+      - ../other:/data
+`,
+			want: []string{ruleHostBind},
+		},
+		{
+			name: "network mode from a variable",
+			compose: `
+services:
+  a:
+    image: alpine:3
+    network_mode: ${NET:-host}
+`,
+			want: []string{ruleHostNetwork},
+		},
+		{
+			name: "skipped services are not checked",
+			compose: `
+services:
+  db:
+    image: postgres:latest
+    privileged: true
+`,
+			skip: map[string]bool{"db": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := auditCompose(parseTestCompose(t, tt.compose), dir, tt.vars, tt.skip)
+
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.Rule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditPolicyAction(t *testing.T) {
+	policy := &auditPolicy{
+		Rules: map[string]string{rulePrivileged: policyBlock, ruleLatestTag: policyIgnore},
+		Software: map[string]map[string]string{
+			"portainer": {ruleDockerSocket: policyIgnore, rulePrivileged: policyWarn},
+		},
+	}
+
+	tests := []struct {
+		software string
+		rule     string
+		want     string
+	}{
+		{"gitea", rulePrivileged, policyBlock},
+		{"gitea", ruleLatestTag, policyIgnore},
+		{"gitea", ruleDockerSocket, policyWarn},
+		{"portainer", ruleDockerSocket, policyIgnore},
+		{"portainer", rulePrivileged, policyWarn},
+		{"portainer", ruleLatestTag, policyIgnore},
+	}
+
+	for _, tt := range tests {
+		if got := policy.action(tt.software, tt.rule); got != tt.want {
+			t.Errorf("action(%s, %s) = %s, want %s", tt.software, tt.rule, got, tt.want)
+		}
+	}
+
+	findings := policy.apply("gitea", []auditFinding{
+		{Rule: rulePrivileged, Service: "a"},
+		{Rule: ruleLatestTag, Service: "a"},
+	})
+	if len(findings) != 1 || findings[0].Action != policyBlock {
+		t.Errorf("apply = %+v, want only the blocked privileged finding", findings)
+	}
+}
+
+func TestLoadAuditPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		wantErr string
+	}{
+		{
+			name:   "valid policy",
+			policy: `{"rules":{"privileged":"block"},"software":{"portainer":{"docker_socket":"ignore"}}}`,
+		},
+		{
+			name:    "rule names are case-sensitive",
+			policy:  `{"rules":{"Privileged":"block"}}`,
+			wantErr: "unknown rule 'Privileged'",
+		},
+		{
+			name:    "unknown rule for one entry",
+			policy:  `{"software":{"gitea":{"root":"block"}}}`,
+			wantErr: "unknown rule 'root'",
+		},
+		{
+			name:    "unknown action",
+			policy:  `{"rules":{"privileged":"deny"}}`,
+			wantErr: "must be ignore, warn or block",
+		},
+		{
+			name:    "invalid JSON",
+			policy:  `{"rules":`,
+			wantErr: "invalid policy.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			writeFile(t, filepath.Join(s.configPath, auditPolicyFileName), tt.policy)
+
+			_, err := s.loadAuditPolicy()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("loadAuditPolicy: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,620 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"gofr.dev/pkg/gofr"
+)
+
+const (
+	defaultInstanceType = "t3.small"
+	defaultVolumeSizeGB = 20
+	amiParameter        = "/aws/service/ami-amazon-linux-latest/al2023-ami-kernel-default-x86_64"
+
+	// EC2 rejects user data larger than 16 KB
+	maxUserDataSize = 16 * 1024
+)
+
+// userDataTemplate installs Docker on Amazon Linux, moves Docker's data root
+// onto the attached EBS volume and starts the compose project
+var userDataTemplate = template.Must(template.New("user-data").Parse(`#!/bin/bash
+set -euo pipefail
+exec > >(tee /var/log/opensourcer.log /dev/console) 2>&1
+
+echo "opensourcer: waiting for data volume"
+root_disk=$(lsblk -no PKNAME "$(findmnt -no SOURCE /)")
+data_disk=""
+for i in $(seq 1 120); do
+  data_disk=$(lsblk -dpno NAME,TYPE | awk '$2=="disk"{print $1}' | grep -v "/dev/${root_disk}$" | head -n1 || true)
+  [ -n "$data_disk" ] && break
+  sleep 5
+done
+
+if [ -n "$data_disk" ]; then
+  blkid "$data_disk" >/dev/null || mkfs.ext4 -q "$data_disk"
+  mkdir -p /var/lib/docker
+  mount "$data_disk" /var/lib/docker
+  echo "UUID=$(blkid -s UUID -o value "$data_disk") /var/lib/docker ext4 defaults,nofail 0 2" >> /etc/fstab
+fi
+
+echo "opensourcer: installing docker"
+dnf install -y docker
+mkdir -p /usr/local/lib/docker/cli-plugins
+curl -fsSL "https://github.com/docker/compose/releases/latest/download/docker-compose-linux-$(uname -m)" \
+  -o /usr/local/lib/docker/cli-plugins/docker-compose
+chmod +x /usr/local/lib/docker/cli-plugins/docker-compose
+systemctl enable --now docker
+
+echo "opensourcer: starting {{ .Software }}"
+mkdir -p {{ .Dir }}
+echo '{{ .Archive }}' | base64 -d | tar -xz -C {{ .Dir }}
+cd {{ .Dir }}
+docker compose{{ range .ComposeFiles }} -f {{ . }}{{ end }} up -d
+echo "opensourcer: {{ .Software }} is running"
+`))
+
+// awsClient runs AWS CLI commands against a region and optional endpoint,
+// so the same code path works against LocalStack-style stand-ins
+type awsClient struct {
+	region   string
+	endpoint string
+}
+
+// awsOptions are the instance settings of an AWS deploy
+type awsOptions struct {
+	ImageID      string
+	InstanceType string
+	VolumeSize   int
+	AllowedCIDR  string
+	KeyName      string
+}
+
+// awsOptionsFromFlags reads the AWS deploy flags. --allowed-cidr has no
+// default, so exposing a deployment to the whole internet is an explicit choice.
+func awsOptionsFromFlags(c *gofr.Context) (awsOptions, error) {
+	opts := awsOptions{
+		ImageID:      c.Param("ami"),
+		InstanceType: c.Param("instance-type"),
+		VolumeSize:   defaultVolumeSizeGB,
+		AllowedCIDR:  c.Param("allowed-cidr"),
+		KeyName:      c.Param("key-name"),
+	}
+	if opts.InstanceType == "" {
+		opts.InstanceType = defaultInstanceType
+	}
+	if size := c.Param("volume-size"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n <= 0 {
+			return opts, fmt.Errorf("invalid --volume-size %q: expected a size in GB", size)
+		}
+		opts.VolumeSize = n
+	}
+
+	if opts.AllowedCIDR == "" {
+		return opts, fmt.Errorf("--allowed-cidr is required for AWS deploys, e.g. --allowed-cidr=203.0.113.0/24\n\n" +
+			"Use --allowed-cidr=0.0.0.0/0 to open the exposed ports to the whole internet")
+	}
+	if _, _, err := net.ParseCIDR(opts.AllowedCIDR); err != nil {
+		return opts, fmt.Errorf("invalid --allowed-cidr %q: %w", opts.AllowedCIDR, err)
+	}
+
+	return opts, nil
+}
+
+// newAWSClient builds a client from --region/--aws-endpoint flags, falling back to the environment
+func newAWSClient(c *gofr.Context) *awsClient {
+	client := &awsClient{
+		region:   c.Param("region"),
+		endpoint: c.Param("aws-endpoint"),
+	}
+	if client.region == "" {
+		client.region = os.Getenv("AWS_REGION")
+	}
+	if client.endpoint == "" {
+		client.endpoint = os.Getenv("AWS_ENDPOINT_URL")
+	}
+	return client
+}
+
+func (a *awsClient) run(out interface{}, args ...string) error {
+	fullArgs := append(args, "--output", "json")
+	if a.region != "" {
+		fullArgs = append(fullArgs, "--region", a.region)
+	}
+	if a.endpoint != "" {
+		fullArgs = append(fullArgs, "--endpoint-url", a.endpoint)
+	}
+
+	cmd := exec.Command("aws", fullArgs...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	data, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("aws %s failed: %s", strings.Join(args[:2], " "), strings.TrimSpace(stderr.String()))
+	}
+
+	if out != nil && len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("unexpected output from aws %s: %w", strings.Join(args[:2], " "), err)
+		}
+	}
+
+	return nil
+}
+
+func checkAWSAvailable() error {
+	cmd := exec.Command("aws", "--version")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("aws CLI is not installed")
+	}
+	return nil
+}
+
+func (s *Service) deployAWS(c *gofr.Context, software string, detail *CatalogDetail, inputs map[string]string, externals []externalService, overrides *resourceOverrides) (interface{}, error) {
+	if err := checkAWSAvailable(); err != nil {
+		return nil, fmt.Errorf("the aws CLI is required for AWS deployment: %w", err)
+	}
+
+	opts, err := awsOptionsFromFlags(c)
+	if err != nil {
+		return nil, err
+	}
+
+	deployDir := s.awsDeploymentDir(software)
+	backup, err := backupDeployFiles(deployDir, filepath.Join(s.catalogPath, software))
+	if err != nil {
+		return nil, err
+	}
+
+	prepared, err := s.prepareDeployment("aws", software, detail, inputs, externals, overrides)
+	if err != nil {
+		return nil, s.failDeploy(c, software, deployDir, backup, false, err)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("\n🚀 Deploying %s to AWS...\n\n", detail.Name))
+
+	if len(prepared.Findings) > 0 {
+		output.WriteString("Security audit:\n")
+		writeFindings(&output, "  ", prepared.Findings)
+		output.WriteString("\n")
+	}
+	if opts.AllowedCIDR == "0.0.0.0/0" || opts.AllowedCIDR == "::/0" {
+		output.WriteString(fmt.Sprintf("⚠️  WARNING: the exposed ports are open to the whole internet (--allowed-cidr=%s)\n\n", opts.AllowedCIDR))
+	}
+
+	deployment, err := s.provisionAWS(newAWSClient(c), opts, prepared, detail, inputs, c.Param("keep-on-failure") == "true")
+	if err != nil {
+		return nil, s.failDeploy(c, software, deployDir, backup, false, err)
+	}
+	resources := deployment.AWS
+
+	output.WriteString("✅ Deployment successful!\n\n")
+	output.WriteString(fmt.Sprintf("  Software: %s\n", detail.Name))
+	output.WriteString(fmt.Sprintf("  Instance: %s (%s)\n", resources.InstanceID, resources.Region))
+	output.WriteString(fmt.Sprintf("  Volume: %s\n", resources.VolumeID))
+	if resources.PublicIP != "" && prepared.Port > 0 {
+		output.WriteString(fmt.Sprintf("  URL: http://%s:%d\n", resources.PublicIP, prepared.Port))
+	}
+	output.WriteString("\n  The instance installs Docker and starts the services on first boot, which takes a few minutes.\n")
+
+	writeGeneratedCredentials(&output, prepared.EnvVars, inputs, prepared.DBPasswordUsed)
+
+	output.WriteString("\nUseful commands:\n")
+	output.WriteString(fmt.Sprintf("  opensourcer logs %s    - View instance console output\n", software))
+	output.WriteString(fmt.Sprintf("  opensourcer stop %s    - Stop the instance\n", software))
+	output.WriteString(fmt.Sprintf("  opensourcer destroy %s - Terminate the instance and delete its resources\n", software))
+
+	return output.String(), nil
+}
+
+// provisionAWS creates the AWS resources for a prepared deployment and saves
+// its record. If anything fails, the resources created so far are removed
+// unless keepOnFailure is set.
+func (s *Service) provisionAWS(client *awsClient, opts awsOptions, prepared *deploymentPlan, detail *CatalogDetail, inputs map[string]string, keepOnFailure bool) (*LocalDeployment, error) {
+	userData, err := buildUserData(prepared.Software, prepared.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.New().String()
+	resources, err := client.provision(opts, prepared.Software, id, userData, exposedHostPorts(detail, prepared.Compose, prepared.Externals))
+	if err != nil {
+		if resources != nil {
+			if keepOnFailure {
+				err = fmt.Errorf("%w\n\nAWS resources were kept: instance %q, volume %q, security group %q",
+					err, resources.InstanceID, resources.VolumeID, resources.SecurityGroupID)
+			} else if teardownErr := client.teardown(resources); teardownErr != nil {
+				err = fmt.Errorf("%w (rollback incomplete: %v)", err, teardownErr)
+			}
+		}
+		return nil, err
+	}
+
+	deployment := LocalDeployment{
+		ID:        id,
+		Software:  prepared.Software,
+		Target:    "aws",
+		Status:    "running",
+		Directory: prepared.Dir,
+		Port:      prepared.Port,
+		Inputs:    inputs,
+		AWS:       resources,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	for _, ext := range prepared.Externals {
+		deployment.ExternalServices = append(deployment.ExternalServices, ext.Service)
+	}
+
+	if err := s.addDeployment(deployment); err != nil {
+		if teardownErr := client.teardown(resources); teardownErr != nil {
+			err = fmt.Errorf("%w (rollback incomplete: %v)", err, teardownErr)
+		}
+		return nil, err
+	}
+
+	return &deployment, nil
+}
+
+// provision creates the security group, instance and data volume. On error the
+// resources created so far are returned so the caller can tear them down.
+func (a *awsClient) provision(opts awsOptions, software, id, userData string, ports []int) (*AWSResources, error) {
+	resources := &AWSResources{Region: a.region, Endpoint: a.endpoint}
+
+	imageID := opts.ImageID
+	if imageID == "" {
+		var param struct {
+			Parameter struct {
+				Value string `json:"Value"`
+			} `json:"Parameter"`
+		}
+		if err := a.run(&param, "ssm", "get-parameter", "--name", amiParameter); err != nil {
+			return nil, fmt.Errorf("failed to resolve AMI, pass --ami=<id>: %w", err)
+		}
+		imageID = param.Parameter.Value
+	}
+
+	name := fmt.Sprintf("opensourcer-%s-%s", software, id[:8])
+
+	// Security group with a rule per exposed port
+	var group struct {
+		GroupID string `json:"GroupId"`
+	}
+	if err := a.run(&group, "ec2", "create-security-group", "--group-name", name, "--description", "opensourcer "+software); err != nil {
+		return nil, err
+	}
+	resources.SecurityGroupID = group.GroupID
+
+	for _, port := range ports {
+		if err := a.run(nil, "ec2", "authorize-security-group-ingress", "--group-id", group.GroupID,
+			"--protocol", "tcp", "--port", strconv.Itoa(port), "--cidr", opts.AllowedCIDR); err != nil {
+			return resources, err
+		}
+	}
+
+	// Instance running the compose project. The user data carries the .env
+	// secrets, so it is passed in a private file rather than on the command
+	// line, and the metadata service requires IMDSv2 tokens with a hop limit
+	// of 1, which containers behind Docker's bridge network can't reach.
+	userDataPath, err := writeUserDataFile(userData)
+	if err != nil {
+		return resources, err
+	}
+	defer os.Remove(userDataPath)
+
+	tags := fmt.Sprintf("ResourceType=instance,Tags=[{Key=Name,Value=%s},{Key=opensourcer:deployment,Value=%s}]", name, id)
+	args := []string{"ec2", "run-instances", "--image-id", imageID, "--instance-type", opts.InstanceType, "--count", "1",
+		"--security-group-ids", group.GroupID, "--user-data", "file://" + userDataPath, "--tag-specifications", tags,
+		"--metadata-options", "HttpTokens=required,HttpPutResponseHopLimit=1,HttpEndpoint=enabled"}
+	if opts.KeyName != "" {
+		args = append(args, "--key-name", opts.KeyName)
+	}
+
+	var run struct {
+		Instances []struct {
+			InstanceID string `json:"InstanceId"`
+			Placement  struct {
+				AvailabilityZone string `json:"AvailabilityZone"`
+			} `json:"Placement"`
+		} `json:"Instances"`
+	}
+	if err := a.run(&run, args...); err != nil {
+		return resources, err
+	}
+	if len(run.Instances) == 0 {
+		return resources, fmt.Errorf("run-instances returned no instance")
+	}
+	resources.InstanceID = run.Instances[0].InstanceID
+	zone := run.Instances[0].Placement.AvailabilityZone
+
+	if err := a.run(nil, "ec2", "wait", "instance-running", "--instance-ids", resources.InstanceID); err != nil {
+		return resources, err
+	}
+
+	// EBS volume holding Docker's data root
+	var volume struct {
+		VolumeID string `json:"VolumeId"`
+	}
+	if err := a.run(&volume, "ec2", "create-volume", "--availability-zone", zone, "--size", strconv.Itoa(opts.VolumeSize),
+		"--volume-type", "gp3", "--tag-specifications", fmt.Sprintf("ResourceType=volume,Tags=[{Key=Name,Value=%s}]", name)); err != nil {
+		return resources, err
+	}
+	resources.VolumeID = volume.VolumeID
+
+	if err := a.run(nil, "ec2", "wait", "volume-available", "--volume-ids", volume.VolumeID); err != nil {
+		return resources, err
+	}
+	if err := a.run(nil, "ec2", "attach-volume", "--volume-id", volume.VolumeID, "--instance-id", resources.InstanceID, "--device", "/dev/sdf"); err != nil {
+		return resources, err
+	}
+
+	resources.PublicIP, _ = a.publicIP(resources.InstanceID)
+
+	return resources, nil
+}
+
+// writeUserDataFile writes the instance user data to a temporary file only the
+// current user can read, so its secrets don't show up in the process list
+func writeUserDataFile(userData string) (string, error) {
+	f, err := os.CreateTemp("", "opensourcer-user-data-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to write user data: %w", err)
+	}
+	defer f.Close()
+
+	if err := f.Chmod(0600); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write user data: %w", err)
+	}
+	if _, err := f.WriteString(userData); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write user data: %w", err)
+	}
+	return f.Name(), nil
+}
+
+func (a *awsClient) publicIP(instanceID string) (string, error) {
+	var described struct {
+		Reservations []struct {
+			Instances []struct {
+				PublicIPAddress string `json:"PublicIpAddress"`
+			} `json:"Instances"`
+		} `json:"Reservations"`
+	}
+	if err := a.run(&described, "ec2", "describe-instances", "--instance-ids", instanceID); err != nil {
+		return "", err
+	}
+	for _, r := range described.Reservations {
+		for _, i := range r.Instances {
+			return i.PublicIPAddress, nil
+		}
+	}
+	return "", nil
+}
+
+// teardown terminates the instance and deletes its volume and security group, best effort
+func (a *awsClient) teardown(resources *AWSResources) error {
+	var errs []string
+
+	if resources.InstanceID != "" {
+		if err := a.run(nil, "ec2", "terminate-instances", "--instance-ids", resources.InstanceID); err != nil {
+			errs = append(errs, err.Error())
+		} else if err := a.run(nil, "ec2", "wait", "instance-terminated", "--instance-ids", resources.InstanceID); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if resources.VolumeID != "" {
+		_ = a.run(nil, "ec2", "wait", "volume-available", "--volume-ids", resources.VolumeID)
+		if err := a.run(nil, "ec2", "delete-volume", "--volume-id", resources.VolumeID); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if resources.SecurityGroupID != "" {
+		if err := a.run(nil, "ec2", "delete-security-group", "--group-id", resources.SecurityGroupID); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to remove AWS resources: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func clientFor(resources *AWSResources) *awsClient {
+	return &awsClient{region: resources.Region, endpoint: resources.Endpoint}
+}
+
+func (s *Service) getAWSLogs(deployment *LocalDeployment) (interface{}, error) {
+	var console struct {
+		Output string `json:"Output"`
+	}
+	if err := clientFor(deployment.AWS).run(&console, "ec2", "get-console-output", "--instance-id", deployment.AWS.InstanceID, "--latest"); err != nil {
+		return nil, fmt.Errorf("failed to get logs: %w", err)
+	}
+
+	return fmt.Sprintf("\n📋 Console output for %s (%s)\n%s\n%s", deployment.Software, deployment.AWS.InstanceID, strings.Repeat("─", 60), console.Output), nil
+}
+
+func (s *Service) stopAWS(deployment *LocalDeployment) (interface{}, error) {
+	if err := clientFor(deployment.AWS).run(nil, "ec2", "stop-instances", "--instance-ids", deployment.AWS.InstanceID); err != nil {
+		return nil, fmt.Errorf("failed to stop instance: %w", err)
+	}
+
+	if err := s.updateDeploymentStatus(deployment.ID, "stopped"); err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("\n⏹️  Stopped '%s' (%s)\n\nUse 'opensourcer start %s' to restart\n", deployment.Software, deployment.AWS.InstanceID, deployment.Software), nil
+}
+
+func (s *Service) startAWS(deployment *LocalDeployment) (interface{}, error) {
+	client := clientFor(deployment.AWS)
+	if err := client.run(nil, "ec2", "start-instances", "--instance-ids", deployment.AWS.InstanceID); err != nil {
+		return nil, fmt.Errorf("failed to start instance: %w", err)
+	}
+	if err := client.run(nil, "ec2", "wait", "instance-running", "--instance-ids", deployment.AWS.InstanceID); err != nil {
+		return nil, fmt.Errorf("failed to start instance: %w", err)
+	}
+
+	// Stopped instances get a new public IP when they start again
+	if ip, err := client.publicIP(deployment.AWS.InstanceID); err == nil {
+		deployment.AWS.PublicIP = ip
+	}
+	err := s.updateDeployment(deployment.ID, func(d *LocalDeployment) {
+		d.Status = "running"
+		d.AWS = deployment.AWS
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("\n✅ Started '%s'\n", deployment.Software))
+	if deployment.AWS.PublicIP != "" && deployment.Port > 0 {
+		output.WriteString(fmt.Sprintf("\n  URL: http://%s:%d\n", deployment.AWS.PublicIP, deployment.Port))
+	}
+
+	return output.String(), nil
+}
+
+func (s *Service) destroyAWS(deployment *LocalDeployment) (interface{}, error) {
+	if err := clientFor(deployment.AWS).teardown(deployment.AWS); err != nil {
+		return nil, err
+	}
+
+	// Remove the local staging directory
+	_ = os.RemoveAll(deployment.Directory)
+
+	return nil, nil
+}
+
+// buildUserData renders the instance boot script with the deployment directory embedded
+func buildUserData(software, deployDir string) (string, error) {
+	var archive bytes.Buffer
+	if err := tarGzDir(deployDir, &archive); err != nil {
+		return "", fmt.Errorf("failed to archive deployment: %w", err)
+	}
+
+	remoteDir := "/opt/opensourcer/" + software
+	composeFiles := []string{"docker-compose.yaml"}
+	for _, name := range composeOverrideFiles {
+		if _, err := os.Stat(filepath.Join(deployDir, name)); err == nil {
+			composeFiles = append(composeFiles, name)
+		}
+	}
+
+	var script bytes.Buffer
+	err := userDataTemplate.Execute(&script, map[string]interface{}{
+		"Software":     software,
+		"Dir":          remoteDir,
+		"Archive":      base64.StdEncoding.EncodeToString(archive.Bytes()),
+		"ComposeFiles": composeFiles,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if script.Len() > maxUserDataSize {
+		return "", fmt.Errorf("deployment files are too large for EC2 user data (%d bytes, limit %d)", script.Len(), maxUserDataSize)
+	}
+
+	return script.String(), nil
+}
+
+// exposedHostPorts returns the host ports that need to be reachable from outside.
+// When the catalog marks services as exposed, only their ports are opened.
+func exposedHostPorts(detail *CatalogDetail, compose *ComposeFile, externals []externalService) []int {
+	dropped := make(map[string]bool)
+	for _, ext := range externals {
+		dropped[ext.Service] = true
+	}
+
+	onlyExposed := false
+	for _, info := range detail.Services {
+		if info.Exposed {
+			onlyExposed = true
+		}
+	}
+
+	seen := make(map[int]bool)
+	var ports []int
+	for _, name := range compose.serviceNames() {
+		if dropped[name] || (onlyExposed && !detail.Services[name].Exposed) {
+			continue
+		}
+		for _, p := range compose.Services[name].Ports {
+			for port := p.Host; port > 0 && port < p.Host+max(p.Count, 1); port++ {
+				if !seen[port] {
+					seen[port] = true
+					ports = append(ports, port)
+				}
+			}
+		}
+	}
+
+	return ports
+}
+
+// tarGzDir writes a gzipped tarball of dir's contents to w
+func tarGzDir(dir string, w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
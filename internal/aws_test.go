@@ -0,0 +1,201 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeAWS is a stand-in for the aws CLI. It logs every call, answers with
+// canned JSON and fails the subcommand named in FAKE_AWS_FAIL.
+const fakeAWS = `#!/bin/sh
+echo "$*" >> "$FAKE_AWS_LOG"
+if [ "$2" = "$FAKE_AWS_FAIL" ]; then
+  echo "An error occurred (InternalError) when calling $2" >&2
+  exit 255
+fi
+for arg in "$@"; do
+  case "$arg" in
+    file://*)
+      f="${arg#file://}"
+      ls -l "$f" | cut -c1-10 > "$FAKE_AWS_LOG.mode"
+      cp "$f" "$FAKE_AWS_LOG.userdata"
+      ;;
+  esac
+done
+case "$2" in
+  get-parameter) echo '{"Parameter":{"Value":"ami-fake"}}' ;;
+  create-security-group) echo '{"GroupId":"sg-0123"}' ;;
+  run-instances) echo '{"Instances":[{"InstanceId":"i-0123","Placement":{"AvailabilityZone":"us-east-1a"}}]}' ;;
+  create-volume) echo '{"VolumeId":"vol-0123"}' ;;
+  describe-instances) echo '{"Reservations":[{"Instances":[{"PublicIpAddress":"203.0.113.10"}]}]}' ;;
+esac
+`
+
+// setupFakeAWS puts the fake aws CLI first on PATH and returns its call log
+func setupFakeAWS(t *testing.T, fail string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake aws CLI is a shell script")
+	}
+
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "aws"), []byte(fakeAWS), 0755); err != nil {
+		t.Fatal(err)
+	}
+	log := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("FAKE_AWS_LOG", log)
+	t.Setenv("FAKE_AWS_FAIL", fail)
+	return log
+}
+
+func readCalls(t *testing.T, log string) []string {
+	t.Helper()
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+// hasCall reports whether a logged call starts with prefix
+func hasCall(calls []string, prefix string) bool {
+	for _, call := range calls {
+		if strings.HasPrefix(call, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func testAWSPlan(t *testing.T, s *Service) (*deploymentPlan, *CatalogDetail) {
+	t.Helper()
+	dir := s.awsDeploymentDir("app")
+	writeFile(t, filepath.Join(dir, "docker-compose.yaml"), "services:\n  web:\n    image: app:1\n    ports:\n      - \"8080:80\"\n")
+	writeFile(t, filepath.Join(dir, ".env"), "DB_PASSWORD=s3cret\n")
+
+	compose, err := loadComposeFile(filepath.Join(dir, "docker-compose.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	detail := &CatalogDetail{Name: "App", Services: map[string]ServiceInfo{"web": {Exposed: true}}}
+	return &deploymentPlan{Software: "app", Dir: dir, Compose: compose, Port: 8080}, detail
+}
+
+func TestProvisionAWS(t *testing.T) {
+	log := setupFakeAWS(t, "")
+	s := newTestService(t)
+	plan, detail := testAWSPlan(t, s)
+
+	client := &awsClient{region: "us-east-1", endpoint: "http://localhost:4566"}
+	opts := awsOptions{InstanceType: defaultInstanceType, VolumeSize: 30, AllowedCIDR: "203.0.113.0/24"}
+
+	deployment, err := s.provisionAWS(client, opts, plan, detail, nil, false)
+	if err != nil {
+		t.Fatalf("provisionAWS: %v", err)
+	}
+
+	deployments, err := s.readDeployments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deployments) != 1 || deployments[0].ID != deployment.ID {
+		t.Fatalf("records = %+v, want the new deployment", deployments)
+	}
+	want := AWSResources{
+		Region:          "us-east-1",
+		Endpoint:        "http://localhost:4566",
+		InstanceID:      "i-0123",
+		VolumeID:        "vol-0123",
+		SecurityGroupID: "sg-0123",
+		PublicIP:        "203.0.113.10",
+	}
+	if got := deployments[0].AWS; got == nil || *got != want {
+		t.Errorf("AWS resources = %+v, want %+v", got, want)
+	}
+
+	calls := readCalls(t, log)
+	for _, prefix := range []string{
+		"ssm get-parameter",
+		"ec2 authorize-security-group-ingress --group-id sg-0123 --protocol tcp --port 8080 --cidr 203.0.113.0/24",
+		"ec2 create-volume --availability-zone us-east-1a --size 30",
+		"ec2 attach-volume --volume-id vol-0123 --instance-id i-0123",
+	} {
+		if !hasCall(calls, prefix) {
+			t.Errorf("missing call %q in %q", prefix, calls)
+		}
+	}
+	for _, call := range calls {
+		if !strings.Contains(call, "--endpoint-url http://localhost:4566") {
+			t.Errorf("call %q doesn't use the endpoint", call)
+		}
+		if strings.Contains(call, "s3cret") {
+			t.Errorf("call %q has the secrets on the command line", call)
+		}
+	}
+
+	mode, err := os.ReadFile(log + ".mode")
+	if err != nil {
+		t.Fatalf("user data wasn't passed as a file: %v", err)
+	}
+	if strings.TrimSpace(string(mode)) != "-rw-------" {
+		t.Errorf("user data file mode = %s, want -rw-------", mode)
+	}
+	userData, err := os.ReadFile(log + ".userdata")
+	if err != nil || !strings.HasPrefix(string(userData), "#!/bin/bash") {
+		t.Errorf("user data = %.40q, %v", userData, err)
+	}
+}
+
+func TestProvisionAWSRollsBack(t *testing.T) {
+	log := setupFakeAWS(t, "attach-volume")
+	s := newTestService(t)
+	plan, detail := testAWSPlan(t, s)
+
+	client := &awsClient{region: "us-east-1"}
+	opts := awsOptions{ImageID: "ami-given", InstanceType: defaultInstanceType, VolumeSize: 20, AllowedCIDR: "0.0.0.0/0"}
+
+	if _, err := s.provisionAWS(client, opts, plan, detail, nil, false); err == nil || !strings.Contains(err.Error(), "InternalError") {
+		t.Fatalf("error = %v, want the attach-volume failure", err)
+	}
+
+	calls := readCalls(t, log)
+	if hasCall(calls, "ssm get-parameter") {
+		t.Error("the AMI was resolved although --ami was given")
+	}
+	for _, prefix := range []string{
+		"ec2 terminate-instances --instance-ids i-0123",
+		"ec2 delete-volume --volume-id vol-0123",
+		"ec2 delete-security-group --group-id sg-0123",
+	} {
+		if !hasCall(calls, prefix) {
+			t.Errorf("missing teardown call %q in %q", prefix, calls)
+		}
+	}
+
+	deployments, err := s.readDeployments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deployments) != 0 {
+		t.Errorf("records = %+v, want none after a failed deploy", deployments)
+	}
+}
+
+func TestTeardownAWS(t *testing.T) {
+	log := setupFakeAWS(t, "delete-volume")
+
+	client := &awsClient{region: "us-east-1"}
+	err := client.teardown(&AWSResources{InstanceID: "i-0123", VolumeID: "vol-0123", SecurityGroupID: "sg-0123"})
+	if err == nil || !strings.Contains(err.Error(), "delete-volume") {
+		t.Fatalf("error = %v, want the delete-volume failure", err)
+	}
+
+	// A failed step doesn't stop the rest of the teardown
+	if calls := readCalls(t, log); !hasCall(calls, "ec2 delete-security-group --group-id sg-0123") {
+		t.Errorf("security group wasn't deleted: %q", calls)
+	}
+}
@@ -0,0 +1,307 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+const (
+	bundleManifestFile = "manifest.json"
+	bundleImagesFile   = "images.tar"
+
+	// bundleVersion is the current bundle manifest format
+	bundleVersion = 1
+)
+
+// bundleManifest describes the contents of an offline bundle
+type bundleManifest struct {
+	Version   int                    `json:"version"`
+	Software  string                 `json:"software"`
+	CreatedAt time.Time              `json:"created_at"`
+	Images    map[string]LockedImage `json:"images"`
+}
+
+// bundle is an extracted offline bundle
+type bundle struct {
+	dir      string
+	manifest bundleManifest
+
+	// service reads the catalog entry from the bundle instead of the local catalog
+	service *Service
+}
+
+// Bundle packages a catalog entry and the images it runs into one archive
+// that can be deployed without network access
+func (s *Service) Bundle(c *gofr.Context) (interface{}, error) {
+	software := getArg(c)
+	if software == "" {
+		return nil, fmt.Errorf("usage: opensourcer bundle <software> [--output <file>]")
+	}
+
+	detail, err := s.getCatalogDetail(software)
+	if err != nil {
+		return nil, err
+	}
+
+	compose, err := loadComposeFile(s.getComposePath(software))
+	if err != nil {
+		return nil, fmt.Errorf("docker-compose.yaml not found for '%s'", software)
+	}
+
+	if err := checkDockerAvailable(); err != nil {
+		return nil, fmt.Errorf("docker is required to bundle images: %w", err)
+	}
+
+	outPath := getFlagValue(c, "output")
+	if outPath == "" {
+		outPath = software + "-bundle.tar.gz"
+	}
+
+	staging, err := os.MkdirTemp("", "opensourcer-bundle-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	entryDir := filepath.Join(staging, "catalog", software)
+	if err := os.MkdirAll(entryDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := copyDir(filepath.Join(s.catalogPath, software), entryDir); err != nil {
+		return nil, fmt.Errorf("failed to copy catalog files: %w", err)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("\n📦 Bundling %s...\n\n", detail.Name))
+
+	// Resolve the images with the catalog defaults, the same way a deploy would
+	vars := prepareEnvVars(detail, map[string]string{})
+	manifest := bundleManifest{
+		Version:   bundleVersion,
+		Software:  software,
+		CreatedAt: time.Now(),
+		Images:    make(map[string]LockedImage),
+	}
+	var refs []string
+	pulled := make(map[string]LockedImage)
+	for _, name := range compose.serviceNames() {
+		if compose.Services[name].Image == "" {
+			output.WriteString(fmt.Sprintf("  - %s: built from source, not bundled\n", name))
+			continue
+		}
+		image := interpolateCompose(compose.Services[name].Image, vars)
+
+		locked, ok := pulled[image]
+		if !ok {
+			if locked, err = pullForBundle(image); err != nil {
+				return nil, err
+			}
+			pulled[image] = locked
+			refs = append(refs, image)
+		}
+		manifest.Images[name] = locked
+		output.WriteString(fmt.Sprintf("  ✓ %s: %s\n", name, image))
+	}
+
+	if len(refs) > 0 {
+		var stderr bytes.Buffer
+		save := exec.Command("docker", append([]string{"save", "-o", filepath.Join(staging, bundleImagesFile)}, refs...)...)
+		save.Stderr = &stderr
+		if err := save.Run(); err != nil {
+			return nil, fmt.Errorf("docker save failed: %s", strings.TrimSpace(stderr.String()))
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(staging, bundleManifestFile), data, 0644); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", outPath, err)
+	}
+	if err := tarGzDir(staging, f); err != nil {
+		f.Close()
+		_ = os.Remove(outPath)
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(outPath)
+	if err != nil {
+		return nil, err
+	}
+
+	output.WriteString(fmt.Sprintf("\n✅ Wrote %s (%s)\n", outPath, humanSize(info.Size())))
+	output.WriteString(fmt.Sprintf("\nDeploy it on a machine without network access with:\n  opensourcer deploy --from-bundle %s\n", filepath.Base(outPath)))
+
+	return output.String(), nil
+}
+
+// pullForBundle pulls an image and records its digest and local ID
+func pullForBundle(image string) (LockedImage, error) {
+	var stderr bytes.Buffer
+	pull := exec.Command("docker", "pull", "--quiet", image)
+	pull.Stderr = &stderr
+	if err := pull.Run(); err != nil {
+		return LockedImage{}, fmt.Errorf("failed to pull %s: %s", image, strings.TrimSpace(stderr.String()))
+	}
+
+	digest, err := imageDigest(image)
+	if err != nil {
+		return LockedImage{}, err
+	}
+	id, err := imageID(image)
+	if err != nil {
+		return LockedImage{}, err
+	}
+
+	return LockedImage{Image: image, Digest: digest, ID: id}, nil
+}
+
+// openBundle extracts a bundle into a temporary directory and reads its
+// manifest. software, if set, must match the bundled entry. Callers must
+// Close the bundle.
+func (s *Service) openBundle(path, software string) (*bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+
+	dir, err := os.MkdirTemp("", "opensourcer-bundle-")
+	if err != nil {
+		return nil, err
+	}
+	b := &bundle{dir: dir}
+
+	if err := extractTarGz(f, dir); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("invalid bundle %s: %w", path, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, bundleManifestFile))
+	if err != nil {
+		b.Close()
+		return nil, fmt.Errorf("invalid bundle %s: %s is missing", path, bundleManifestFile)
+	}
+	if err := json.Unmarshal(data, &b.manifest); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("invalid bundle %s: %w", path, err)
+	}
+	if b.manifest.Version > bundleVersion {
+		b.Close()
+		return nil, fmt.Errorf("bundle version %d was written by a newer opensourcer (this version supports %d)", b.manifest.Version, bundleVersion)
+	}
+	if software != "" && software != b.manifest.Software {
+		b.Close()
+		return nil, fmt.Errorf("bundle contains '%s', not '%s'", b.manifest.Software, software)
+	}
+
+	bundled := *s
+	bundled.catalogPath = filepath.Join(dir, "catalog")
+	bundled.pinnedImages = b.manifest.Images
+	b.service = &bundled
+
+	return b, nil
+}
+
+// loadImages loads the bundled images into Docker and checks that each tag
+// now refers to the image that was bundled
+func (b *bundle) loadImages() error {
+	if len(b.manifest.Images) == 0 {
+		return nil
+	}
+
+	var stderr bytes.Buffer
+	load := exec.Command("docker", "load", "--quiet", "-i", filepath.Join(b.dir, bundleImagesFile))
+	load.Stderr = &stderr
+	if err := load.Run(); err != nil {
+		return fmt.Errorf("docker load failed: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	for _, name := range sortedKeys(b.manifest.Images) {
+		img := b.manifest.Images[name]
+		id, err := imageID(img.Image)
+		if err != nil {
+			return err
+		}
+		if id != img.ID {
+			return fmt.Errorf("image %s for service %s does not match the bundle (got %s, want %s)", img.Image, name, id, img.ID)
+		}
+	}
+	return nil
+}
+
+// Close removes the extracted bundle
+func (b *bundle) Close() {
+	_ = os.RemoveAll(b.dir)
+}
+
+// extractTarGz unpacks a gzipped tarball into dir, rejecting entries that
+// would land outside it
+func extractTarGz(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("entry %s is outside the archive", header.Name)
+		}
+		target := filepath.Join(dir, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("entry %s is not a regular file or directory", header.Name)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type testTarEntry struct {
+	name     string
+	typeflag byte
+	content  string
+	linkname string
+}
+
+func buildTarGz(t *testing.T, entries []testTarEntry) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Typeflag: e.typeflag, Mode: 0644, Linkname: e.linkname}
+		if e.typeflag == tar.TypeReg {
+			header.Size = int64(len(e.content))
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if e.typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestExtractTarGz(t *testing.T) {
+	tests := []struct {
+		name      string
+		entries   []testTarEntry
+		wantErr   string
+		wantFiles map[string]string
+	}{
+		{
+			name: "files and directories",
+			entries: []testTarEntry{
+				{name: "catalog/", typeflag: tar.TypeDir},
+				{name: "catalog/gitea/app.json", typeflag: tar.TypeReg, content: "{}"},
+				{name: "./manifest.json", typeflag: tar.TypeReg, content: `{"version":1}`},
+			},
+			wantFiles: map[string]string{
+				"catalog/gitea/app.json": "{}",
+				"manifest.json":          `{"version":1}`,
+			},
+		},
+		{
+			name:    "parent directory",
+			entries: []testTarEntry{{name: "../evil", typeflag: tar.TypeReg, content: "x"}},
+			wantErr: "outside the archive",
+		},
+		{
+			name:    "nested parent directory",
+			entries: []testTarEntry{{name: "catalog/../../evil", typeflag: tar.TypeReg, content: "x"}},
+			wantErr: "outside the archive",
+		},
+		{
+			name:    "absolute path",
+			entries: []testTarEntry{{name: "/tmp/evil", typeflag: tar.TypeReg, content: "x"}},
+			wantErr: "outside the archive",
+		},
+		{
+			name:    "parent directory after dot",
+			entries: []testTarEntry{{name: "./../evil", typeflag: tar.TypeReg, content: "x"}},
+			wantErr: "outside the archive",
+		},
+		{
+			name:    "directory outside",
+			entries: []testTarEntry{{name: "../evil/", typeflag: tar.TypeDir}},
+			wantErr: "outside the archive",
+		},
+		{
+			name: "names starting with dots",
+			entries: []testTarEntry{
+				{name: "..config", typeflag: tar.TypeReg, content: "a"},
+				{name: "catalog/..data/x", typeflag: tar.TypeReg, content: "b"},
+				{name: "catalog/gitea/../app.json", typeflag: tar.TypeReg, content: "c"},
+			},
+			wantFiles: map[string]string{
+				"..config":         "a",
+				"catalog/..data/x": "b",
+				"catalog/app.json": "c",
+			},
+		},
+		{
+			name:    "symlink",
+			entries: []testTarEntry{{name: "link", typeflag: tar.TypeSymlink, linkname: "/etc"}},
+			wantErr: "not a regular file or directory",
+		},
+		{
+			name:    "hard link",
+			entries: []testTarEntry{{name: "link", typeflag: tar.TypeLink, linkname: "/etc/passwd"}},
+			wantErr: "not a regular file or directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := t.TempDir()
+			dir := filepath.Join(parent, "bundle")
+			if err := os.Mkdir(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			err := extractTarGz(buildTarGz(t, tt.entries), dir)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				if pathExists(filepath.Join(parent, "evil")) {
+					t.Fatal("an entry was written outside the extraction directory")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractTarGz: %v", err)
+			}
+
+			for name, want := range tt.wantFiles {
+				got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+				if err != nil {
+					t.Fatalf("reading %s: %v", name, err)
+				}
+				if string(got) != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractTarGzRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	writeFile(t, filepath.Join(src, "catalog", "gitea", "docker-compose.yaml"), "services: {}\n")
+	writeFile(t, filepath.Join(src, bundleManifestFile), `{"version":1}`)
+
+	var archive bytes.Buffer
+	if err := tarGzDir(src, &archive); err != nil {
+		t.Fatalf("tarGzDir: %v", err)
+	}
+
+	dst := t.TempDir()
+	if err := extractTarGz(&archive, dst); err != nil {
+		t.Fatalf("extractTarGz: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dst, "catalog", "gitea", "docker-compose.yaml"))
+	if err != nil || string(got) != "services: {}\n" {
+		t.Errorf("docker-compose.yaml = %q, %v", got, err)
+	}
+}
+
+func TestOpenBundle(t *testing.T) {
+	manifest := `{"version":1,"software":"app","images":{"web":{"image":"app:1","digest":"sha256:aaa","id":"sha256:aaa"}}}`
+
+	tests := []struct {
+		name     string
+		entries  []testTarEntry
+		software string
+		wantErr  string
+	}{
+		{
+			name: "valid",
+			entries: []testTarEntry{
+				{name: bundleManifestFile, typeflag: tar.TypeReg, content: manifest},
+				{name: "catalog/app/app.json", typeflag: tar.TypeReg, content: `{"name":"App"}`},
+			},
+			software: "app",
+		},
+		{
+			name:    "newer version",
+			entries: []testTarEntry{{name: bundleManifestFile, typeflag: tar.TypeReg, content: `{"version":99,"software":"app"}`}},
+			wantErr: "newer opensourcer",
+		},
+		{
+			name:     "other software",
+			entries:  []testTarEntry{{name: bundleManifestFile, typeflag: tar.TypeReg, content: manifest}},
+			software: "gitea",
+			wantErr:  "bundle contains 'app', not 'gitea'",
+		},
+		{
+			name:    "no manifest",
+			entries: []testTarEntry{{name: "catalog/app/app.json", typeflag: tar.TypeReg, content: "{}"}},
+			wantErr: "manifest.json is missing",
+		},
+		{
+			name: "entry outside the archive",
+			entries: []testTarEntry{
+				{name: bundleManifestFile, typeflag: tar.TypeReg, content: manifest},
+				{name: "../../evil", typeflag: tar.TypeReg, content: "x"},
+			},
+			wantErr: "outside the archive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			tmp := t.TempDir()
+			t.Setenv("TMPDIR", tmp)
+
+			path := filepath.Join(t.TempDir(), "app-bundle.tar.gz")
+			if err := os.WriteFile(path, buildTarGz(t, tt.entries).Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := s.openBundle(path, tt.software)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				if left, _ := os.ReadDir(tmp); len(left) != 0 {
+					t.Errorf("extracted files left behind: %v", left)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("openBundle: %v", err)
+			}
+			defer b.Close()
+
+			detail, err := b.service.getCatalogDetail("app")
+			if err != nil || detail.Name != "App" {
+				t.Errorf("catalog entry read from the bundle = %+v, %v", detail, err)
+			}
+			if b.service.pinnedImages["web"].ID != "sha256:aaa" || s.pinnedImages != nil {
+				t.Errorf("pinned images = %+v, want the bundle's on the bundle service only", b.service.pinnedImages)
+			}
+		})
+	}
+}
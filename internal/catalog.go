@@ -1,6 +1,8 @@
 package internal
 
 import (
+	"bytes"
+	"crypto/ed25519"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,65 +12,114 @@ import (
 	"gofr.dev/pkg/gofr"
 )
 
-// Update updates the local catalog from the repository
+// Update downloads the catalog into a temporary directory, verifies its
+// signature and only then swaps it into place, keeping the current catalog
+// if anything fails
 func (s *Service) Update(c *gofr.Context) (interface{}, error) {
-	if _, err := os.Stat(s.catalogPath); os.IsNotExist(err) {
-		return s.cloneCatalog()
-	}
+	skipVerify := c.Param("skip-verify") == "true"
 
-	return s.pullCatalog()
-}
+	var key ed25519.PublicKey
+	if !skipVerify {
+		var err error
+		if key, err = s.catalogVerifyKey(); err != nil {
+			return nil, err
+		}
+	}
 
-func (s *Service) cloneCatalog() (interface{}, error) {
 	if err := os.MkdirAll(filepath.Dir(s.catalogPath), 0755); err != nil {
 		return nil, fmt.Errorf("failed to create catalog directory: %w", err)
 	}
 
 	tempDir := s.catalogPath + ".tmp"
+	_ = os.RemoveAll(tempDir)
 	defer os.RemoveAll(tempDir)
 
 	cmd := exec.Command("git", "clone", "--depth", "1", catalogRepoURL, tempDir)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("failed to clone catalog: %w", err)
+		return nil, fmt.Errorf("failed to download catalog: %s", strings.TrimSpace(stderr.String()))
 	}
 
-	// Move catalog contents (excluding .git)
-	srcCatalog := tempDir
-	if err := os.Rename(srcCatalog, s.catalogPath); err != nil {
-		return nil, fmt.Errorf("failed to move catalog: %w", err)
+	// Remove .git directory to keep it clean
+	_ = os.RemoveAll(filepath.Join(tempDir, ".git"))
+
+	if !skipVerify {
+		if err := verifyCatalog(tempDir, key); err != nil {
+			return nil, fmt.Errorf("catalog verification failed: %w\n\nThe downloaded catalog was discarded and the existing one kept", err)
+		}
 	}
 
-	// Remove .git directory to keep it clean
-	targetGitDir := filepath.Join(s.catalogPath, ".git")
-	_ = os.RemoveAll(targetGitDir)
+	// Compare before swapping, while both catalogs are on disk. The report is
+	// informational, so a failure to compare doesn't stop a verified update.
+	existed := pathExists(s.catalogPath)
+	var diff *catalogDiff
+	var diffErr error
+	if existed {
+		diff, diffErr = diffCatalogs(s.catalogPath, tempDir)
+	}
 
-	return "\n✅ Catalog downloaded successfully!\n\nRun 'opensourcer catalog' to see available software.\n", nil
-}
+	if err := s.swapCatalog(tempDir); err != nil {
+		return nil, err
+	}
+
+	index, err := s.writeCatalogIndex()
+	if err != nil {
+		return nil, err
+	}
 
-func (s *Service) pullCatalog() (interface{}, error) {
-	if _, err := os.Stat(s.catalogPath); os.IsNotExist(err) {
-		return s.cloneCatalog()
+	var output strings.Builder
+	if existed {
+		output.WriteString(fmt.Sprintf("\n✅ Catalog updated successfully! %d software indexed.\n", len(index.Entries)))
+		// A damaged deployments.json shouldn't fail the update; it only hides the upgrade notes
+		deployments, _ := s.readDeployments()
+		if diffErr != nil {
+			output.WriteString(fmt.Sprintf("\n  Changes could not be listed: %v\n", diffErr))
+		} else {
+			writeCatalogDiff(&output, diff, deployments)
+		}
+	} else {
+		output.WriteString(fmt.Sprintf("\n✅ Catalog downloaded successfully! %d software indexed.\n\nRun 'opensourcer catalog' to see available software.\n", len(index.Entries)))
 	}
+	if skipVerify {
+		output.WriteString("\n⚠️  The catalog signature was not verified (--skip-verify).\n")
+	}
+
+	return output.String(), nil
+}
 
-	// Check if it's a git repo
-	gitDir := filepath.Join(s.catalogPath, ".git")
-	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
-		// Not a git repo, re-clone
-		_ = os.RemoveAll(s.catalogPath)
-		return s.cloneCatalog()
+// swapCatalog replaces the catalog with newDir, restoring the previous
+// catalog if the new one can't be moved into place
+func (s *Service) swapCatalog(newDir string) error {
+	oldDir := s.catalogPath + ".old"
+	_ = os.RemoveAll(oldDir)
+
+	existed := pathExists(s.catalogPath)
+	if existed {
+		if err := os.Rename(s.catalogPath, oldDir); err != nil {
+			return fmt.Errorf("failed to replace catalog: %w", err)
+		}
 	}
 
-	cmd := exec.Command("git", "-C", s.catalogPath, "pull", "--rebase")
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("failed to update catalog: %w", err)
+	if err := os.Rename(newDir, s.catalogPath); err != nil {
+		if existed {
+			_ = os.Rename(oldDir, s.catalogPath)
+		}
+		return fmt.Errorf("failed to replace catalog: %w", err)
 	}
 
-	return "\n✅ Catalog updated successfully!\n", nil
+	_ = os.RemoveAll(oldDir)
+	return nil
 }
 
 // listCatalogItems returns a list of software slugs in the catalog
 func (s *Service) listCatalogItems() ([]string, error) {
-	entries, err := os.ReadDir(s.catalogPath)
+	return catalogItems(s.catalogPath)
+}
+
+// catalogItems returns the entry slugs in a catalog directory
+func catalogItems(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, err
 	}
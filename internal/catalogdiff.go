@@ -0,0 +1,230 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// catalogDiff describes how a downloaded catalog differs from the current one
+type catalogDiff struct {
+	Added   []string
+	Removed []string
+	Changed []catalogChange
+}
+
+// catalogChange describes how one catalog entry changed
+type catalogChange struct {
+	Slug          string
+	Images        []string
+	NewInputs     []string
+	RemovedInputs []string
+	Files         []string
+}
+
+// diffCatalogs compares the catalog in oldDir with the one in newDir
+func diffCatalogs(oldDir, newDir string) (*catalogDiff, error) {
+	oldItems, err := catalogItems(oldDir)
+	if err != nil {
+		return nil, err
+	}
+	newItems, err := catalogItems(newDir)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &catalogDiff{}
+	inOld := make(map[string]bool)
+	for _, slug := range oldItems {
+		inOld[slug] = true
+	}
+	for _, slug := range newItems {
+		if !inOld[slug] {
+			diff.Added = append(diff.Added, slug)
+			continue
+		}
+		delete(inOld, slug)
+
+		change, err := diffCatalogEntry(slug, filepath.Join(oldDir, slug), filepath.Join(newDir, slug))
+		if err != nil {
+			return nil, err
+		}
+		if change != nil {
+			diff.Changed = append(diff.Changed, *change)
+		}
+	}
+	diff.Removed = sortedKeys(inOld)
+
+	return diff, nil
+}
+
+// diffCatalogEntry compares two versions of an entry, returning nil if no file changed
+func diffCatalogEntry(slug, oldDir, newDir string) (*catalogChange, error) {
+	oldHashes, err := entryFileHashes(oldDir)
+	if err != nil {
+		return nil, err
+	}
+	newHashes, err := entryFileHashes(newDir)
+	if err != nil {
+		return nil, err
+	}
+
+	change := &catalogChange{Slug: slug}
+	for name, hash := range newHashes {
+		if oldHashes[name] != hash {
+			change.Files = append(change.Files, name)
+		}
+	}
+	for name := range oldHashes {
+		if _, ok := newHashes[name]; !ok {
+			change.Files = append(change.Files, name)
+		}
+	}
+	if len(change.Files) == 0 {
+		return nil, nil
+	}
+	sort.Strings(change.Files)
+
+	// Image versions, as declared in the compose file
+	oldCompose, _ := loadComposeFile(findComposeFile(oldDir))
+	newCompose, _ := loadComposeFile(findComposeFile(newDir))
+	if oldCompose != nil && newCompose != nil {
+		for _, name := range newCompose.serviceNames() {
+			image := newCompose.Services[name].Image
+			previous, ok := oldCompose.Services[name]
+			switch {
+			case !ok:
+				change.Images = append(change.Images, fmt.Sprintf("%s: new service (%s)", name, image))
+			case previous.Image != image:
+				change.Images = append(change.Images, fmt.Sprintf("%s: %s → %s", name, previous.Image, image))
+			}
+		}
+		for _, name := range oldCompose.serviceNames() {
+			if _, ok := newCompose.Services[name]; !ok {
+				change.Images = append(change.Images, fmt.Sprintf("%s: service removed", name))
+			}
+		}
+	}
+
+	oldDetail, _ := readCatalogDetail(oldDir)
+	newDetail, _ := readCatalogDetail(newDir)
+	if oldDetail != nil && newDetail != nil {
+		for _, key := range sortedKeys(newDetail.Inputs) {
+			if _, ok := oldDetail.Inputs[key]; !ok {
+				change.NewInputs = append(change.NewInputs, key)
+			}
+		}
+		for _, key := range sortedKeys(oldDetail.Inputs) {
+			if _, ok := newDetail.Inputs[key]; !ok {
+				change.RemovedInputs = append(change.RemovedInputs, key)
+			}
+		}
+	}
+
+	return change, nil
+}
+
+// entryFileHashes returns the sha256 of every file in an entry, keyed by relative path
+func entryFileHashes(dir string) (map[string]string, error) {
+	hashes := make(map[string]string)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		hash, err := fileSHA256(path)
+		if err != nil {
+			return err
+		}
+		hashes[filepath.ToSlash(rel)] = hash
+		return nil
+	})
+	return hashes, err
+}
+
+// readCatalogDetail parses the app.json of the entry in dir
+func readCatalogDetail(dir string) (*CatalogDetail, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "app.json"))
+	if err != nil {
+		return nil, err
+	}
+	var detail CatalogDetail
+	if err := json.Unmarshal(data, &detail); err != nil {
+		return nil, err
+	}
+	return &detail, nil
+}
+
+// writeCatalogDiff reports the catalog changes and which deployments they affect
+func writeCatalogDiff(output *strings.Builder, diff *catalogDiff, deployments []LocalDeployment) {
+	if len(diff.Added)+len(diff.Removed)+len(diff.Changed) == 0 {
+		output.WriteString("\nNo catalog entries changed.\n")
+		return
+	}
+
+	if len(diff.Added) > 0 {
+		output.WriteString(fmt.Sprintf("\n  Added (%d): %s\n", len(diff.Added), strings.Join(diff.Added, ", ")))
+	}
+	if len(diff.Removed) > 0 {
+		output.WriteString(fmt.Sprintf("\n  Removed (%d): %s\n", len(diff.Removed), strings.Join(diff.Removed, ", ")))
+	}
+	if len(diff.Changed) > 0 {
+		output.WriteString(fmt.Sprintf("\n  Changed (%d):\n", len(diff.Changed)))
+		for _, change := range diff.Changed {
+			output.WriteString(fmt.Sprintf("    %s\n", change.Slug))
+			writeCatalogChange(output, "      ", change)
+		}
+	}
+
+	changed := make(map[string]catalogChange)
+	for _, change := range diff.Changed {
+		changed[change.Slug] = change
+	}
+	removed := make(map[string]bool)
+	for _, slug := range diff.Removed {
+		removed[slug] = true
+	}
+
+	var notes strings.Builder
+	for _, d := range deployments {
+		if removed[d.Software] {
+			notes.WriteString(fmt.Sprintf("  ⚠️  %s: removed from the catalog; the deployment keeps running but can't be upgraded\n", d.Software))
+			continue
+		}
+		change, ok := changed[d.Software]
+		if !ok {
+			continue
+		}
+		if d.Target == "aws" {
+			notes.WriteString(fmt.Sprintf("  ⬆️  %s: the catalog entry changed; redeploy to apply it\n", d.Software))
+		} else {
+			notes.WriteString(fmt.Sprintf("  ⬆️  %s: upgrade available, run 'opensourcer upgrade %s'\n", d.Software, d.Software))
+		}
+		writeCatalogChange(&notes, "      ", change)
+	}
+	if notes.Len() > 0 {
+		output.WriteString("\nYour deployments:\n")
+		output.WriteString(notes.String())
+	}
+}
+
+func writeCatalogChange(output *strings.Builder, indent string, change catalogChange) {
+	for _, image := range change.Images {
+		output.WriteString(indent + image + "\n")
+	}
+	if len(change.NewInputs) > 0 {
+		output.WriteString(fmt.Sprintf("%snew inputs: %s\n", indent, strings.Join(change.NewInputs, ", ")))
+	}
+	if len(change.RemovedInputs) > 0 {
+		output.WriteString(fmt.Sprintf("%sremoved inputs: %s\n", indent, strings.Join(change.RemovedInputs, ", ")))
+	}
+	if len(change.Images) == 0 && len(change.NewInputs) == 0 && len(change.RemovedInputs) == 0 {
+		output.WriteString(fmt.Sprintf("%sfiles changed: %s\n", indent, strings.Join(change.Files, ", ")))
+	}
+}
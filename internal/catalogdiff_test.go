@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTestEntry(t *testing.T, catalogDir, slug, appJSON, compose string) {
+	t.Helper()
+	writeFile(t, filepath.Join(catalogDir, slug, "app.json"), appJSON)
+	writeFile(t, filepath.Join(catalogDir, slug, "docker-compose.yaml"), compose)
+}
+
+func TestDiffCatalogs(t *testing.T) {
+	oldDir, newDir := t.TempDir(), t.TempDir()
+
+	// Unchanged
+	writeTestEntry(t, oldDir, "ghost", `{"name":"Ghost"}`, "services:\n  ghost:\n    image: ghost:5\n")
+	writeTestEntry(t, newDir, "ghost", `{"name":"Ghost"}`, "services:\n  ghost:\n    image: ghost:5\n")
+
+	// New image version, a new service and a new input
+	writeTestEntry(t, oldDir, "gitea",
+		`{"name":"Gitea","inputs":{"domain":{"type":"text"},"legacy":{"type":"text"}}}`,
+		"services:\n  gitea:\n    image: gitea/gitea:1.21\n  cache:\n    image: redis:7\n")
+	writeTestEntry(t, newDir, "gitea",
+		`{"name":"Gitea","inputs":{"domain":{"type":"text"},"smtp_host":{"type":"text"}}}`,
+		"services:\n  gitea:\n    image: gitea/gitea:1.22\n  db:\n    image: postgres:16\n")
+
+	// Removed and added entries
+	writeTestEntry(t, oldDir, "wekan", `{"name":"Wekan"}`, "services: {}\n")
+	writeTestEntry(t, newDir, "plausible", `{"name":"Plausible"}`, "services: {}\n")
+
+	diff, err := diffCatalogs(oldDir, newDir)
+	if err != nil {
+		t.Fatalf("diffCatalogs: %v", err)
+	}
+
+	if !reflect.DeepEqual(diff.Added, []string{"plausible"}) {
+		t.Errorf("added = %v", diff.Added)
+	}
+	if !reflect.DeepEqual(diff.Removed, []string{"wekan"}) {
+		t.Errorf("removed = %v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 {
+		t.Fatalf("changed = %+v, want only gitea", diff.Changed)
+	}
+
+	want := catalogChange{
+		Slug: "gitea",
+		Images: []string{
+			"db: new service (postgres:16)",
+			"gitea: gitea/gitea:1.21 → gitea/gitea:1.22",
+			"cache: service removed",
+		},
+		NewInputs:     []string{"smtp_host"},
+		RemovedInputs: []string{"legacy"},
+		Files:         []string{"app.json", "docker-compose.yaml"},
+	}
+	if !reflect.DeepEqual(diff.Changed[0], want) {
+		t.Errorf("change = %+v\nwant %+v", diff.Changed[0], want)
+	}
+}
+
+func TestDiffCatalogsFileOnlyChange(t *testing.T) {
+	oldDir, newDir := t.TempDir(), t.TempDir()
+	writeTestEntry(t, oldDir, "gitea", `{"name":"Gitea"}`, "services: {}\n")
+	writeTestEntry(t, newDir, "gitea", `{"name":"Gitea"}`, "services: {}\n")
+	writeFile(t, filepath.Join(newDir, "gitea", "config", "app.ini"), "[server]\n")
+
+	diff, err := diffCatalogs(oldDir, newDir)
+	if err != nil {
+		t.Fatalf("diffCatalogs: %v", err)
+	}
+	if len(diff.Changed) != 1 || !reflect.DeepEqual(diff.Changed[0].Files, []string{"config/app.ini"}) {
+		t.Errorf("changed = %+v, want the added config file", diff.Changed)
+	}
+	if len(diff.Changed[0].Images)+len(diff.Changed[0].NewInputs)+len(diff.Changed[0].RemovedInputs) != 0 {
+		t.Errorf("change reports image or input changes: %+v", diff.Changed[0])
+	}
+}
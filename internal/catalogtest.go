@@ -0,0 +1,513 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+	"gopkg.in/yaml.v3"
+)
+
+// JUnit XML report written by catalog test
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// smokeTest runs the steps of a catalog test and records them as test cases
+type smokeTest struct {
+	suite   junitTestSuite
+	output  *strings.Builder
+	aborted bool
+}
+
+// step runs fn as a test case, unless an earlier fatal step failed. A failing
+// fatal step skips the steps after it.
+func (t *smokeTest) step(name string, fatal bool, fn func() (string, error)) {
+	if t.aborted {
+		t.skip(name, "an earlier step failed")
+		return
+	}
+	if err := t.record(name, fn); err != nil && fatal {
+		t.aborted = true
+	}
+}
+
+// record runs fn as a test case regardless of earlier failures
+func (t *smokeTest) record(name string, fn func() (string, error)) error {
+	start := time.Now()
+	out, err := fn()
+	elapsed := time.Since(start)
+
+	tc := junitTestCase{
+		Name:      name,
+		ClassName: "opensourcer.catalog." + t.suite.Name,
+		Time:      fmt.Sprintf("%.3f", elapsed.Seconds()),
+		SystemOut: out,
+	}
+	if err != nil {
+		tc.Failure = &junitFailure{Message: err.Error(), Text: out}
+		t.suite.Failures++
+		t.output.WriteString(fmt.Sprintf("  ✗ %s: %v\n", name, err))
+	} else {
+		t.output.WriteString(fmt.Sprintf("  ✓ %s (%s)\n", name, elapsed.Round(100*time.Millisecond)))
+	}
+	t.suite.Tests++
+	t.suite.Cases = append(t.suite.Cases, tc)
+	return err
+}
+
+func (t *smokeTest) skip(name, reason string) {
+	t.suite.Tests++
+	t.suite.Skipped++
+	t.suite.Cases = append(t.suite.Cases, junitTestCase{
+		Name:      name,
+		ClassName: "opensourcer.catalog." + t.suite.Name,
+		Time:      "0.000",
+		Skipped:   &junitSkipped{Message: reason},
+	})
+	t.output.WriteString(fmt.Sprintf("  - %s: skipped, %s\n", name, reason))
+}
+
+// TestCatalogEntry deploys a catalog entry into a throwaway compose project,
+// waits for it to become ready, probes its URL and runs its smoke checks,
+// then removes everything and writes a JUnit report
+func (s *Service) TestCatalogEntry(c *gofr.Context) (interface{}, error) {
+	slug := getSecondArg()
+	if dir := getFlagValue(c, "catalog-dir"); dir != "" {
+		var err error
+		if s, slug, err = s.withCatalogDir(dir, slug); err != nil {
+			return nil, err
+		}
+	}
+	if slug == "" {
+		return nil, fmt.Errorf("usage: opensourcer catalog test <slug> [--catalog-dir <dir>] [--timeout 5m] [--report <file>] [--keep]")
+	}
+
+	detail, err := s.getCatalogDetail(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := 5 * time.Minute
+	if value := getFlagValue(c, "timeout"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid --timeout %q, expected a duration like 5m", value)
+		}
+		timeout = d
+	}
+
+	reportPath := getFlagValue(c, "report")
+	if reportPath == "" {
+		reportPath = slug + "-junit.xml"
+	}
+
+	if err := checkDockerAvailable(); err != nil {
+		return nil, fmt.Errorf("docker is required to test a catalog entry: %w", err)
+	}
+
+	// A temporary directory gives the test its own compose project name
+	dir, err := os.MkdirTemp("", "opensourcer-test-"+slug+"-")
+	if err != nil {
+		return nil, err
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("\n🧪 Testing catalog entry %s (%s)\n", slug, detail.Name))
+	output.WriteString(strings.Repeat("-", 60) + "\n\n")
+
+	t := &smokeTest{
+		suite:  junitTestSuite{Name: slug, Timestamp: time.Now().UTC().Format(time.RFC3339)},
+		output: &output,
+	}
+	started := time.Now()
+
+	var compose *ComposeFile
+	var url string
+	t.step("deploy", true, func() (string, error) {
+		var log strings.Builder
+		var err error
+		if compose, url, err = s.prepareTestProject(dir, slug, detail); err != nil {
+			return "", err
+		}
+
+		policy, err := s.loadAuditPolicy()
+		if err != nil {
+			return "", err
+		}
+		vars, _ := parseEnvFile(filepath.Join(dir, ".env"))
+		if err := s.blockedFindingsError(policy.apply(slug, auditCompose(compose, dir, vars, nil))); err != nil {
+			return "", err
+		}
+
+		if err := runHooks(&log, dir, detail, hookPreDeploy); err != nil {
+			return log.String(), err
+		}
+		if out, err := composeCommand(dir, "up", "-d").CombinedOutput(); err != nil {
+			return log.String() + string(out), fmt.Errorf("docker compose up failed")
+		}
+		err = runHooks(&log, dir, detail, hookPostDeploy)
+		return log.String(), err
+	})
+
+	t.step("ready", true, func() (string, error) {
+		return waitForServices(dir, timeout)
+	})
+
+	if url == "" && !t.aborted {
+		t.skip("http", "the entry publishes no port")
+	} else {
+		t.step("http", false, func() (string, error) {
+			return probeURL(url, timeout)
+		})
+	}
+
+	for i, check := range detail.Checks {
+		name := check.Name
+		if name == "" {
+			name = fmt.Sprintf("check %d", i+1)
+		}
+		t.step(name, false, func() (string, error) {
+			return runSmokeCheck(dir, detail, compose, url, check)
+		})
+	}
+
+	if c.Param("keep") == "true" {
+		output.WriteString(fmt.Sprintf("\n  Test project kept in %s\n", dir))
+	} else {
+		removed := false
+		_ = t.record("destroy", func() (string, error) {
+			var log strings.Builder
+			hookErr := runHooks(&log, dir, detail, hookPreDestroy)
+			out, err := composeCommand(dir, "down", "-v", "--remove-orphans").CombinedOutput()
+			if err != nil {
+				return log.String() + string(out), fmt.Errorf("docker compose down failed")
+			}
+			removed = os.RemoveAll(dir) == nil
+			return log.String(), hookErr
+		})
+
+		// Without the project directory the containers and volumes couldn't be removed
+		if !removed {
+			output.WriteString(fmt.Sprintf("\n  Test project kept in %s; run 'docker compose down -v' there to remove it\n", dir))
+		}
+	}
+
+	t.suite.Time = fmt.Sprintf("%.3f", time.Since(started).Seconds())
+	report, err := xml.MarshalIndent(t.suite, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(reportPath, append([]byte(xml.Header), append(report, '\n')...), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write report: %w", err)
+	}
+
+	output.WriteString(fmt.Sprintf("\n%d passed, %d failed, %d skipped\n", t.suite.Tests-t.suite.Failures-t.suite.Skipped, t.suite.Failures, t.suite.Skipped))
+	output.WriteString(fmt.Sprintf("JUnit report: %s\n", reportPath))
+
+	// Fail the command so CI can gate on it
+	if t.suite.Failures > 0 || t.aborted {
+		return nil, fmt.Errorf("%s\ncatalog test for '%s' failed", output.String(), slug)
+	}
+
+	return output.String(), nil
+}
+
+// prepareTestProject copies a catalog entry into dir with generated inputs.
+// Published ports are moved to free loopback ports and fixed container and
+// project names are dropped, so the test can't collide with a deployment. It
+// returns the compose file and the URL of the exposed port, if any.
+func (s *Service) prepareTestProject(dir, slug string, detail *CatalogDetail) (*ComposeFile, string, error) {
+	catalogDir := filepath.Join(s.catalogPath, slug)
+	if err := copyDir(catalogDir, dir); err != nil {
+		return nil, "", fmt.Errorf("failed to copy catalog files: %w", err)
+	}
+
+	inputs := make(map[string]string)
+	for key, input := range detail.Inputs {
+		switch {
+		case input.Default != "":
+			inputs[key] = input.Default
+		case input.Type == "password":
+			// generated by prepareEnvVars
+		case input.Type == "email":
+			inputs[key] = "test@example.com"
+		default:
+			inputs[key] = "opensourcer-test"
+		}
+	}
+	envVars := prepareEnvVars(detail, inputs)
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte(buildEnvFile(envVars)), 0644); err != nil {
+		return nil, "", fmt.Errorf("failed to write .env file: %w", err)
+	}
+
+	composePath := filepath.Join(dir, "docker-compose.yaml")
+	data, err := os.ReadFile(composePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("docker-compose.yaml not found for '%s'", slug)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil, "", fmt.Errorf("invalid compose file %s", composePath)
+	}
+	root := doc.Content[0]
+	removeMappingKey(root, "name")
+
+	remapped := make(map[int]int)
+	if services := mappingValue(root, "services"); services != nil && services.Kind == yaml.MappingNode {
+		for i := 1; i < len(services.Content); i += 2 {
+			svc := services.Content[i]
+			removeMappingKey(svc, "container_name")
+			if err := remapHostPorts(mappingValue(svc, "ports"), envVars, remapped); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	var rewritten bytes.Buffer
+	enc := yaml.NewEncoder(&rewritten)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, "", err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, "", err
+	}
+	if err := os.WriteFile(composePath, rewritten.Bytes(), 0644); err != nil {
+		return nil, "", err
+	}
+
+	compose, err := loadComposeFile(composePath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	port := findExposedPort(string(data))
+	if port == 0 {
+		port = findExposedPort(interpolateCompose(string(data), envVars))
+	}
+	url := ""
+	if testPort, ok := remapped[port]; ok {
+		url = fmt.Sprintf("http://127.0.0.1:%d", testPort)
+	} else if port := compose.firstHostPort(); port > 0 {
+		url = fmt.Sprintf("http://127.0.0.1:%d", port)
+	}
+
+	return compose, url, nil
+}
+
+// remapHostPorts rewrites the published ports of a service to free loopback
+// ports, recording original to new port in remapped
+func remapHostPorts(ports *yaml.Node, envVars map[string]string, remapped map[int]int) error {
+	if ports == nil || ports.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	for _, item := range ports.Content {
+		var spec ComposePort
+		if item.Kind == yaml.MappingNode {
+			if err := item.Decode(&spec); err != nil {
+				return err
+			}
+		} else {
+			parsed, err := parsePortSpec(interpolateCompose(item.Value, envVars))
+			if err != nil {
+				return err
+			}
+			spec = parsed
+		}
+		if spec.Host == 0 {
+			continue
+		}
+		if spec.Count > 1 {
+			return fmt.Errorf("published port range %s can't be moved to a free port", spec.hostPorts())
+		}
+
+		port, ok := remapped[spec.Host]
+		if !ok {
+			var err error
+			if port, err = freePort(); err != nil {
+				return err
+			}
+			remapped[spec.Host] = port
+		}
+
+		if item.Kind == yaml.MappingNode {
+			if published := mappingValue(item, "published"); published != nil {
+				published.Value, published.Tag, published.Style = fmt.Sprint(port), "!!str", yaml.DoubleQuotedStyle
+			}
+			if hostIP := mappingValue(item, "host_ip"); hostIP != nil {
+				hostIP.Value = "127.0.0.1"
+			}
+			continue
+		}
+
+		value := fmt.Sprintf("127.0.0.1:%d:%d", port, spec.Container)
+		if spec.Protocol != "" {
+			value += "/" + spec.Protocol
+		}
+		item.Value, item.Tag, item.Style = value, "!!str", yaml.DoubleQuotedStyle
+	}
+	return nil
+}
+
+// freePort asks the kernel for an unused loopback port
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find a free port: %w", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// removeMappingKey deletes key from a YAML mapping
+func removeMappingKey(node *yaml.Node, key string) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// waitForServices waits until every container of the project in dir is
+// running, and healthy if it has a health check. Containers that exited
+// successfully, such as init jobs, count as ready.
+func waitForServices(dir string, timeout time.Duration) (string, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		out, err := composeCommand(dir, "ps", "-a", "--format", "{{.Service}}\t{{.State}}\t{{.Health}}\t{{.ExitCode}}").Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to list containers: %w", err)
+		}
+
+		var pending []string
+		for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			fields := strings.Split(line, "\t")
+			if len(fields) < 4 {
+				continue
+			}
+			service, state, health, exitCode := fields[0], fields[1], fields[2], fields[3]
+			switch {
+			case state == "exited" && exitCode != "0":
+				logs, _ := composeCommand(dir, "logs", "--no-color", "--tail", "50", service).CombinedOutput()
+				return string(logs), fmt.Errorf("service %s exited with status %s", service, exitCode)
+			case health == "unhealthy":
+				return "", fmt.Errorf("service %s is unhealthy", service)
+			case state == "exited":
+			case state != "running" || (health != "" && health != "healthy"):
+				pending = append(pending, service)
+			}
+		}
+
+		if len(pending) == 0 {
+			return string(out), nil
+		}
+		if time.Now().After(deadline) {
+			return string(out), fmt.Errorf("timed out after %s waiting for %s", timeout, strings.Join(pending, ", "))
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
+
+// probeURL waits until url answers without a server error
+func probeURL(url string, timeout time.Duration) (string, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	deadline := time.Now().Add(timeout)
+	for {
+		resp, err := client.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < 500 {
+				return fmt.Sprintf("GET %s: %s\n", url, resp.Status), nil
+			}
+			err = fmt.Errorf("GET %s: %s", url, resp.Status)
+		}
+		if time.Now().After(deadline) {
+			return "", err
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
+
+// runSmokeCheck runs one of the entry's declared checks: an HTTP request to
+// a path of the exposed URL, or a command in one of the services
+func runSmokeCheck(dir string, detail *CatalogDetail, compose *ComposeFile, url string, check SmokeCheck) (string, error) {
+	if check.Path != "" {
+		if url == "" {
+			return "", fmt.Errorf("the entry publishes no port to request %s from", check.Path)
+		}
+		resp, err := (&http.Client{Timeout: 10 * time.Second}).Get(url + check.Path)
+		if err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+
+		out := fmt.Sprintf("GET %s: %s\n", check.Path, resp.Status)
+		if (check.Status != 0 && resp.StatusCode != check.Status) || (check.Status == 0 && resp.StatusCode >= 400) {
+			want := "a success status"
+			if check.Status != 0 {
+				want = fmt.Sprint(check.Status)
+			}
+			return out, fmt.Errorf("GET %s returned %d, want %s", check.Path, resp.StatusCode, want)
+		}
+		return out, nil
+	}
+
+	if len(check.Command) == 0 {
+		return "", fmt.Errorf("check has neither a path nor a command")
+	}
+
+	service := check.Service
+	if service == "" {
+		service = defaultService(detail, compose)
+	}
+	vars, _ := parseEnvFile(filepath.Join(dir, ".env"))
+	command := make([]string, len(check.Command))
+	for i, arg := range check.Command {
+		command[i] = interpolateCompose(arg, vars)
+	}
+
+	out, err := composeCommand(dir, append([]string{"exec", "-T", service}, command...)...).CombinedOutput()
+	if err != nil {
+		return string(out), fmt.Errorf("command failed in %s: %v", service, err)
+	}
+	return string(out), nil
+}
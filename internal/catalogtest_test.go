@@ -0,0 +1,164 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestRemapHostPorts(t *testing.T) {
+	tests := []struct {
+		name    string
+		ports   string
+		envVars map[string]string
+		host    int
+		want    string
+		wantErr string
+	}{
+		{name: "short syntax", ports: `["8080:80"]`, host: 8080, want: `["127.0.0.1:%d:80"]`},
+		{name: "host address and protocol", ports: `["0.0.0.0:5353:53/udp"]`, host: 5353, want: `["127.0.0.1:%d:53/udp"]`},
+		{name: "variable", ports: `["${WEB_PORT:-8080}:80"]`, envVars: map[string]string{"WEB_PORT": "9000"}, host: 9000, want: `["127.0.0.1:%d:80"]`},
+		{name: "variable default", ports: `["${WEB_PORT:-8080}:80"]`, host: 8080, want: `["127.0.0.1:%d:80"]`},
+		{name: "container port only", ports: `["80"]`, want: `["80"]`},
+		{
+			name:  "long syntax",
+			ports: `[{target: 80, published: 8080, host_ip: 0.0.0.0}]`,
+			host:  8080,
+			want:  `[{target: 80, published: "%d", host_ip: 127.0.0.1}]`,
+		},
+		{name: "range", ports: `["8000-8001:8000-8001"]`, wantErr: "can't be moved to a free port"},
+		{name: "unparsable", ports: `["8080:web"]`, wantErr: "invalid port"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc yaml.Node
+			if err := yaml.Unmarshal([]byte(tt.ports), &doc); err != nil {
+				t.Fatal(err)
+			}
+			ports := doc.Content[0]
+
+			remapped := make(map[int]int)
+			err := remapHostPorts(ports, tt.envVars, remapped)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("remapHostPorts: %v", err)
+			}
+
+			want := tt.want
+			if tt.host > 0 {
+				port, ok := remapped[tt.host]
+				if !ok || port == 0 {
+					t.Fatalf("port %d not remapped: %v", tt.host, remapped)
+				}
+				want = fmt.Sprintf(tt.want, port)
+			} else if len(remapped) != 0 {
+				t.Errorf("remapped %v, want nothing", remapped)
+			}
+
+			var got, expected interface{}
+			if err := ports.Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if err := yaml.Unmarshal([]byte(want), &expected); err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(expected) {
+				t.Errorf("ports = %v, want %v", got, expected)
+			}
+		})
+	}
+}
+
+func TestRemapHostPortsSharesPorts(t *testing.T) {
+	remapped := make(map[int]int)
+	var values []string
+	for _, ports := range []string{`["8080:80"]`, `["8080:8080", "9090:90"]`} {
+		var doc yaml.Node
+		if err := yaml.Unmarshal([]byte(ports), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if err := remapHostPorts(doc.Content[0], nil, remapped); err != nil {
+			t.Fatalf("remapHostPorts: %v", err)
+		}
+		for _, item := range doc.Content[0].Content {
+			values = append(values, item.Value)
+		}
+	}
+
+	want := []string{
+		fmt.Sprintf("127.0.0.1:%d:80", remapped[8080]),
+		fmt.Sprintf("127.0.0.1:%d:8080", remapped[8080]),
+		fmt.Sprintf("127.0.0.1:%d:90", remapped[9090]),
+	}
+	if strings.Join(values, " ") != strings.Join(want, " ") || remapped[8080] == remapped[9090] {
+		t.Errorf("ports = %v, want %v", values, want)
+	}
+}
+
+func TestPrepareTestProject(t *testing.T) {
+	s := newTestService(t)
+	writeFile(t, filepath.Join(s.catalogPath, "app", "docker-compose.yaml"), `name: fixed
+services:
+  web:
+    image: app:1
+    container_name: app-web
+    ports:
+      - "${APP_PORT:-3000}:3000"
+  db:
+    image: postgres:16
+    container_name: app-db
+    environment:
+      POSTGRES_PASSWORD: ${DB_PASSWORD}
+`)
+	writeFile(t, filepath.Join(s.catalogPath, "app", "config", "app.ini"), "[server]\n")
+	detail := &CatalogDetail{
+		Name:     "App",
+		Inputs:   map[string]InputConfig{"admin_email": {Type: "email"}, "admin_password": {Type: "password"}, "site_title": {}},
+		Services: map[string]ServiceInfo{"web": {Exposed: true}, "db": {}},
+	}
+
+	dir := t.TempDir()
+	compose, url, err := s.prepareTestProject(dir, "app", detail)
+	if err != nil {
+		t.Fatalf("prepareTestProject: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "docker-compose.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, removed := range []string{"name: fixed", "container_name"} {
+		if strings.Contains(string(data), removed) {
+			t.Errorf("compose file still contains %q:\n%s", removed, data)
+		}
+	}
+
+	port := compose.Services["web"].Ports[0]
+	if port.Host == 3000 || port.Host == 0 || !strings.Contains(string(data), fmt.Sprintf("127.0.0.1:%d:3000", port.Host)) {
+		t.Errorf("web port = %+v, want a free loopback port", port)
+	}
+	if want := fmt.Sprintf("http://127.0.0.1:%d", port.Host); url != want {
+		t.Errorf("url = %s, want %s", url, want)
+	}
+
+	env, err := parseEnvFile(filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env["ADMIN_EMAIL"] != "test@example.com" || env["SITE_TITLE"] != "opensourcer-test" || env["ADMIN_PASSWORD"] == "" || env["DB_PASSWORD"] == "" {
+		t.Errorf(".env = %v, want generated test inputs", env)
+	}
+	if !pathExists(filepath.Join(dir, "config", "app.ini")) {
+		t.Error("catalog files were not copied")
+	}
+}
@@ -0,0 +1,441 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeFile is the subset of a docker-compose.yaml that opensourcer inspects
+type ComposeFile struct {
+	Services map[string]ComposeService `yaml:"services"`
+	Volumes  map[string]interface{}    `yaml:"volumes"`
+}
+
+// ComposeService is the subset of a compose service definition that opensourcer inspects
+type ComposeService struct {
+	Image       string          `yaml:"image"`
+	Command     stringOrList    `yaml:"command"`
+	Environment composeEnv      `yaml:"environment"`
+	Ports       []ComposePort   `yaml:"ports"`
+	Expose      stringOrList    `yaml:"expose"`
+	Volumes     []ComposeVolume `yaml:"volumes"`
+	DependsOn   stringOrList    `yaml:"depends_on"`
+	Privileged  bool            `yaml:"privileged"`
+	NetworkMode string          `yaml:"network_mode"`
+	CapAdd      []string        `yaml:"cap_add"`
+}
+
+// ComposePort is a published or exposed port of a service. A range such as
+// "8000-8010:8000-8010" is recorded as its first ports and Count.
+type ComposePort struct {
+	Host      int
+	Container int
+	Protocol  string
+
+	// Count is the number of consecutive ports, 1 unless the port is a range
+	Count int
+
+	// Variable is set when a port comes from a variable without a default,
+	// so its number is only known once the deployment's env is applied
+	Variable bool
+
+	// Unparsed holds a spec opensourcer can't read. The port is left to docker
+	// compose, which reports it if it is invalid.
+	Unparsed string
+}
+
+// hostPorts returns the published host port or range, e.g. "8080" or "8000-8010"
+func (p ComposePort) hostPorts() string {
+	return portRange(p.Host, p.Count)
+}
+
+// containerPorts returns the container port or range
+func (p ComposePort) containerPorts() string {
+	return portRange(p.Container, p.Count)
+}
+
+func portRange(start, count int) string {
+	if count > 1 {
+		return fmt.Sprintf("%d-%d", start, start+count-1)
+	}
+	return strconv.Itoa(start)
+}
+
+// ComposeVolume is a single volume mount of a service
+type ComposeVolume struct {
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+// composeVarPattern matches ${VAR}, ${VAR:-default}, ${VAR-default} and $VAR references
+var composeVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::?-([^}]*))?\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// loadComposeFile reads and parses a docker-compose.yaml
+func loadComposeFile(path string) (*ComposeFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var compose ComposeFile
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil, fmt.Errorf("invalid compose file %s: %w", path, err)
+	}
+
+	return &compose, nil
+}
+
+// loadComposeFileWithVars reads a docker-compose.yaml and interpolates every
+// value with vars before parsing it, as docker compose does, so ports and
+// other fields set by variables hold the deployment's actual values
+func loadComposeFileWithVars(path string, vars map[string]string) (*ComposeFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid compose file %s: %w", path, err)
+	}
+	interpolateNode(&doc, vars)
+
+	var compose ComposeFile
+	if err := doc.Decode(&compose); err != nil {
+		return nil, fmt.Errorf("invalid compose file %s: %w", path, err)
+	}
+
+	return &compose, nil
+}
+
+// interpolateNode expands variable references in the scalar values of a YAML
+// tree. Mapping keys are left alone, like docker compose does.
+func interpolateNode(node *yaml.Node, vars map[string]string) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if value := interpolateCompose(node.Value, vars); value != node.Value {
+			// Let the new value resolve its own type, so "${PORT:-80}" becomes an int
+			node.Value, node.Tag, node.Style = value, "", 0
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			interpolateNode(node.Content[i], vars)
+		}
+	default:
+		for _, child := range node.Content {
+			interpolateNode(child, vars)
+		}
+	}
+}
+
+// serviceNames returns the compose service names in a stable order
+func (f *ComposeFile) serviceNames() []string {
+	names := make([]string, 0, len(f.Services))
+	for name := range f.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// firstHostPort returns the first published host port, or 0 if none is published
+func (f *ComposeFile) firstHostPort() int {
+	for _, name := range f.serviceNames() {
+		for _, p := range f.Services[name].Ports {
+			if p.Host > 0 {
+				return p.Host
+			}
+		}
+	}
+	return 0
+}
+
+// referencesVar reports whether the service's environment or command refers to variable name
+func (svc ComposeService) referencesVar(name string) bool {
+	values := append([]string{}, svc.Command...)
+	for _, value := range svc.Environment {
+		values = append(values, value)
+	}
+
+	for _, value := range values {
+		for _, m := range composeVarPattern.FindAllStringSubmatch(value, -1) {
+			if m[1] == name || m[3] == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isNamedVolume reports whether a mount source refers to a top-level named volume
+func (f *ComposeFile) isNamedVolume(source string) bool {
+	if source == "" {
+		return false
+	}
+	_, ok := f.Volumes[source]
+	return ok
+}
+
+// isBindMount reports whether a mount source is a host path rather than a named volume
+func isBindMount(source string) bool {
+	return strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") || strings.HasPrefix(source, "~")
+}
+
+// interpolateCompose expands compose-style variable references using vars,
+// falling back to the inline default when a variable is not set
+func interpolateCompose(value string, vars map[string]string) string {
+	return composeVarPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		m := composeVarPattern.FindStringSubmatch(ref)
+		name, def := m[1], m[2]
+		if name == "" {
+			name = m[3]
+		}
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		return def
+	})
+}
+
+// imageName returns an image reference without registry, tag or digest, e.g. "gitea" for "docker.io/gitea/gitea:1.21"
+func imageName(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, "/"); i >= 0 {
+		image = image[i+1:]
+	}
+	name, _, _ := strings.Cut(image, ":")
+	return name
+}
+
+// imageTag returns the tag part of an image reference, or "latest" if none is set
+func imageTag(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[i+1:]
+	}
+	name := image
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return "latest"
+}
+
+type stringOrList []string
+
+func (s *stringOrList) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*s = strings.Fields(node.Value)
+		return nil
+	case yaml.SequenceNode:
+		var list []string
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+		*s = list
+		return nil
+	case yaml.MappingNode:
+		// depends_on long syntax: service name -> condition
+		var m map[string]interface{}
+		if err := node.Decode(&m); err != nil {
+			return err
+		}
+		for key := range m {
+			*s = append(*s, key)
+		}
+		sort.Strings(*s)
+		return nil
+	}
+	return fmt.Errorf("unexpected YAML node at line %d", node.Line)
+}
+
+type composeEnv map[string]string
+
+func (e *composeEnv) UnmarshalYAML(node *yaml.Node) error {
+	env := make(composeEnv)
+
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var list []string
+		if err := node.Decode(&list); err != nil {
+			return err
+		}
+		for _, item := range list {
+			key, value, _ := strings.Cut(item, "=")
+			env[key] = value
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value := node.Content[i+1].Value
+			if node.Content[i+1].Tag == "!!null" {
+				value = ""
+			}
+			env[node.Content[i].Value] = value
+		}
+	default:
+		return fmt.Errorf("unexpected environment at line %d", node.Line)
+	}
+
+	*e = env
+	return nil
+}
+
+func (p *ComposePort) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		var long struct {
+			Target    string `yaml:"target"`
+			Published string `yaml:"published"`
+			Protocol  string `yaml:"protocol"`
+		}
+		if err := node.Decode(&long); err != nil {
+			return err
+		}
+		container, _, containerVar, err := parsePortValue(long.Target)
+		if err != nil {
+			p.Unparsed = long.Target
+			return nil
+		}
+		host, count, hostVar, _ := parsePortValue(long.Published)
+		p.Container, p.Host, p.Count = container, host, max(count, 1)
+		p.Variable = containerVar || hostVar
+		p.Protocol = long.Protocol
+		return nil
+	}
+
+	parsed, err := parsePortSpec(node.Value)
+	if err != nil {
+		p.Unparsed = node.Value
+		return nil
+	}
+	*p = parsed
+	return nil
+}
+
+// parsePortSpec parses the short port syntax, e.g. "8080:80",
+// "127.0.0.1:8080:80/tcp", "80" or "8000-8010:8000-8010". Variable references
+// resolve to their inline defaults; a port set by a variable without one is
+// marked Variable instead of failing.
+func parsePortSpec(spec string) (ComposePort, error) {
+	var port ComposePort
+
+	parts := splitOutsideVars(spec, ':')
+	last := parts[len(parts)-1]
+	if i := strings.LastIndex(last, "/"); i >= 0 && !strings.Contains(last[i:], "}") {
+		last, port.Protocol = last[:i], last[i+1:]
+	}
+
+	container, count, variable, err := parsePortValue(last)
+	if err != nil {
+		return port, fmt.Errorf("invalid port %q", spec)
+	}
+	port.Container, port.Count, port.Variable = container, count, variable
+
+	if len(parts) >= 2 {
+		host, hostCount, hostVariable, err := parsePortValue(parts[len(parts)-2])
+		if err == nil && host > 0 && hostCount != count {
+			return port, fmt.Errorf("invalid port %q: host and container ranges differ in size", spec)
+		}
+		port.Host = host
+		port.Variable = port.Variable || hostVariable
+	}
+
+	return port, nil
+}
+
+// parsePortValue parses one side of a port spec after resolving variable
+// defaults. A value left empty by a variable reports variable instead of an error.
+func parsePortValue(value string) (port, count int, variable bool, err error) {
+	resolved := interpolateCompose(value, nil)
+	if resolved == "" {
+		return 0, 1, value != "", nil
+	}
+	port, count, err = parsePortRange(resolved)
+	return port, count, false, err
+}
+
+// parsePortRange parses a port or a range like "8000-8010", returning the
+// first port and the number of ports
+func parsePortRange(value string) (int, int, error) {
+	first, last, isRange := strings.Cut(value, "-")
+	start, err := strconv.Atoi(first)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !isRange {
+		return start, 1, nil
+	}
+	end, err := strconv.Atoi(last)
+	if err != nil || end < start {
+		return 0, 0, fmt.Errorf("invalid port range %q", value)
+	}
+	return start, end - start + 1, nil
+}
+
+func (v *ComposeVolume) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		var long struct {
+			Source   string `yaml:"source"`
+			Target   string `yaml:"target"`
+			ReadOnly bool   `yaml:"read_only"`
+		}
+		if err := node.Decode(&long); err != nil {
+			return err
+		}
+		v.Source, v.Target, v.ReadOnly = long.Source, long.Target, long.ReadOnly
+		return nil
+	}
+
+	parts := splitOutsideVars(node.Value, ':')
+	switch len(parts) {
+	case 1:
+		v.Target = parts[0]
+	case 2:
+		v.Source, v.Target = parts[0], parts[1]
+	default:
+		v.Source, v.Target = parts[0], parts[1]
+		v.ReadOnly = strings.Contains(parts[2], "ro")
+	}
+	return nil
+}
+
+// splitOutsideVars splits s at sep, except inside ${...} references, so
+// "${DATA:-/srv}:/data" splits into "${DATA:-/srv}" and "/data"
+func splitOutsideVars(s string, sep byte) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}' && depth > 0:
+			depth--
+		case s[i] == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// resolveBindSource resolves a relative bind mount source against the compose directory
+func resolveBindSource(composeDir, source string) string {
+	if strings.HasPrefix(source, "~") {
+		homeDir, _ := os.UserHomeDir()
+		return filepath.Join(homeDir, strings.TrimPrefix(source, "~"))
+	}
+	if filepath.IsAbs(source) {
+		return source
+	}
+	return filepath.Join(composeDir, source)
+}
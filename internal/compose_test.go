@@ -0,0 +1,129 @@
+package internal
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParsePortSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    ComposePort
+		wantErr bool
+	}{
+		{spec: "80", want: ComposePort{Container: 80, Count: 1}},
+		{spec: "8080:80", want: ComposePort{Host: 8080, Container: 80, Count: 1}},
+		{spec: "127.0.0.1:8080:80/tcp", want: ComposePort{Host: 8080, Container: 80, Protocol: "tcp", Count: 1}},
+		{spec: "53:53/udp", want: ComposePort{Host: 53, Container: 53, Protocol: "udp", Count: 1}},
+		{spec: "8000-8010:8000-8010", want: ComposePort{Host: 8000, Container: 8000, Count: 11}},
+		{spec: "9000-9001", want: ComposePort{Container: 9000, Count: 2}},
+		{spec: "${PORT:-3000}:${PORT:-3000}", want: ComposePort{Host: 3000, Container: 3000, Count: 1}},
+		{spec: "${PORT:-3000}:3000", want: ComposePort{Host: 3000, Container: 3000, Count: 1}},
+		{spec: "${BIND:-0.0.0.0}:${PORT:-8080}:80/udp", want: ComposePort{Host: 8080, Container: 80, Protocol: "udp", Count: 1}},
+		{spec: "${PORT}:3000", want: ComposePort{Container: 3000, Count: 1, Variable: true}},
+		{spec: "8000-8010:8000-8005", wantErr: true},
+		{spec: "8010-8000", wantErr: true},
+		{spec: "http", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := parsePortSpec(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePortSpec: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComposePortRangesLoad(t *testing.T) {
+	compose := parseTestCompose(t, `
+services:
+  web:
+    image: nginx:1.25
+    ports:
+      - "8000-8002:8000-8002"
+      - target: 443
+        published: "8443"
+`)
+
+	ports := compose.Services["web"].Ports
+	if len(ports) != 2 {
+		t.Fatalf("got %d ports, want 2", len(ports))
+	}
+	if ports[0].hostPorts() != "8000-8002" || ports[0].containerPorts() != "8000-8002" {
+		t.Errorf("range = %s -> %s", ports[0].hostPorts(), ports[0].containerPorts())
+	}
+	if ports[1].Host != 8443 || ports[1].Container != 443 || ports[1].Count != 1 {
+		t.Errorf("long syntax = %+v", ports[1])
+	}
+}
+
+func TestLoadComposeFileWithVars(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docker-compose.yaml")
+	writeFile(t, path, `
+services:
+  web:
+    image: nginx:${TAG:-1.25}
+    ports:
+      - "${PORT:-3000}:${PORT:-3000}"
+      - "${ADMIN_PORT}:8081"
+      - target: 443
+        published: "${HTTPS_PORT:-8443}"
+`)
+
+	raw, err := loadComposeFile(path)
+	if err != nil {
+		t.Fatalf("loadComposeFile: %v", err)
+	}
+	ports := raw.Services["web"].Ports
+	if ports[0].Host != 3000 || ports[1].Host != 0 || !ports[1].Variable || ports[2].Host != 8443 {
+		t.Errorf("raw ports = %+v", ports)
+	}
+
+	compose, err := loadComposeFileWithVars(path, map[string]string{"PORT": "4000", "ADMIN_PORT": "9000", "TAG": "1.27"})
+	if err != nil {
+		t.Fatalf("loadComposeFileWithVars: %v", err)
+	}
+	web := compose.Services["web"]
+	want := []ComposePort{
+		{Host: 4000, Container: 4000, Count: 1},
+		{Host: 9000, Container: 8081, Count: 1},
+		{Host: 8443, Container: 443, Count: 1},
+	}
+	if !reflect.DeepEqual(web.Ports, want) {
+		t.Errorf("ports = %+v, want %+v", web.Ports, want)
+	}
+	if web.Image != "nginx:1.27" {
+		t.Errorf("image = %s", web.Image)
+	}
+}
+
+func TestSplitOutsideVars(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"data:/data", []string{"data", "/data"}},
+		{"./conf:/etc/conf:ro", []string{"./conf", "/etc/conf", "ro"}},
+		{"${DATA:-/srv/data}:/data", []string{"${DATA:-/srv/data}", "/data"}},
+		{"${A:-x}:${B:-/y}:ro", []string{"${A:-x}", "${B:-/y}", "ro"}},
+		{"/data", []string{"/data"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitOutsideVars(tt.value, ':'); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitOutsideVars(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -13,26 +13,31 @@ import (
 	"gofr.dev/pkg/gofr"
 )
 
-func (s *Service) deployLocal(c *gofr.Context, software string, detail *CatalogDetail, inputs map[string]string) (interface{}, error) {
-	// Check if Docker is available
-	if err := checkDockerAvailable(); err != nil {
-		return nil, fmt.Errorf("docker is required for local deployment: %w", err)
-	}
-
-	// Create deployment directory
-	deployDir := filepath.Join(s.configPath, "deployments", software)
-	if err := os.MkdirAll(deployDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create deployment directory: %w", err)
-	}
+// deploymentPlan is everything a deploy writes and starts, resolved from the catalog
+type deploymentPlan struct {
+	Software   string
+	Dir        string
+	CatalogDir string
+	EnvVars    map[string]string
+	Port       int
+	Compose    *ComposeFile
+	Externals  []externalService
+	Override   string
+	Limits     map[string]resourceLimit
+	Resources  string
+	Findings   []auditFinding
+
+	// DBPasswordUsed is false when the generated DB_PASSWORD was replaced by
+	// an external DSN or only belonged to services the externals replace
+	DBPasswordUsed bool
+}
 
-	// Copy all files from catalog directory to deployment directory
+// planDeployment resolves a deployment from the catalog without writing anything
+func (s *Service) planDeployment(target, software string, detail *CatalogDetail, inputs map[string]string, externals []externalService, overrides *resourceOverrides) (*deploymentPlan, error) {
 	catalogDir := filepath.Join(s.catalogPath, software)
-	if err := copyDir(catalogDir, deployDir); err != nil {
-		return nil, fmt.Errorf("failed to copy catalog files: %w", err)
-	}
 
 	// Read docker-compose.yaml content for port detection
-	composePath := filepath.Join(deployDir, "docker-compose.yaml")
+	composePath := filepath.Join(catalogDir, "docker-compose.yaml")
 	composeContent, err := os.ReadFile(composePath)
 	if err != nil {
 		return nil, fmt.Errorf("docker-compose.yaml not found for '%s'", software)
@@ -41,29 +46,173 @@ func (s *Service) deployLocal(c *gofr.Context, software string, detail *CatalogD
 	// Prepare environment variables
 	envVars := prepareEnvVars(detail, inputs)
 
+	// Replace services with external managed instances
+	for _, ext := range externals {
+		for key, value := range ext.envVars() {
+			envVars[key] = value
+		}
+	}
+
+	compose, err := loadComposeFileWithVars(composePath, envVars)
+	if err != nil {
+		return nil, err
+	}
+	// The uninterpolated file shows which services reference which variables
+	raw, err := loadComposeFile(composePath)
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := resolveResourceLimits(detail, compose, externals, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := s.loadAuditPolicy()
+	if err != nil {
+		return nil, err
+	}
+	dropped := make(map[string]bool)
+	for _, ext := range externals {
+		dropped[ext.Service] = true
+	}
+	dir := s.deploymentDir(software)
+	if target == "aws" {
+		dir = s.awsDeploymentDir(software)
+	}
+	findings := policy.apply(software, auditCompose(compose, dir, envVars, dropped))
+
+	return &deploymentPlan{
+		Software:   software,
+		Dir:        dir,
+		CatalogDir: catalogDir,
+		EnvVars:    envVars,
+		Port:       findExposedPort(string(composeContent)),
+		Compose:    compose,
+		Externals:  externals,
+		Override:   externalOverride(compose, externals),
+		Limits:     limits,
+		Resources:  resourcesOverride(limits),
+		Findings:   findings,
+
+		DBPasswordUsed: dbPasswordUsed(raw, externals),
+	}, nil
+}
+
+// prepareDeployment plans a deployment, then copies the catalog entry into the
+// deployment directory and writes its .env file and compose overrides
+func (s *Service) prepareDeployment(target, software string, detail *CatalogDetail, inputs map[string]string, externals []externalService, overrides *resourceOverrides) (*deploymentPlan, error) {
+	plan, err := s.planDeployment(target, software, detail, inputs, externals, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.blockedFindingsError(plan.Findings); err != nil {
+		return nil, err
+	}
+
+	if err := checkExternalConnectivity(externals); err != nil {
+		return nil, err
+	}
+
+	// Create deployment directory
+	if err := os.MkdirAll(plan.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create deployment directory: %w", err)
+	}
+
+	// Copy all files from catalog directory to deployment directory
+	if err := copyDir(plan.CatalogDir, plan.Dir); err != nil {
+		return nil, fmt.Errorf("failed to copy catalog files: %w", err)
+	}
+
+	overrideFiles := map[string]string{
+		externalOverrideFile:  plan.Override,
+		resourcesOverrideFile: plan.Resources,
+	}
+	for name, content := range overrideFiles {
+		overridePath := filepath.Join(plan.Dir, name)
+		if content == "" {
+			_ = os.Remove(overridePath)
+		} else if err := os.WriteFile(overridePath, []byte(content), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write compose override: %w", err)
+		}
+	}
+
 	// Write .env file
-	envContent := buildEnvFile(envVars)
-	envPath := filepath.Join(deployDir, ".env")
+	envContent := buildEnvFile(plan.EnvVars)
+	envPath := filepath.Join(plan.Dir, ".env")
 	if err := os.WriteFile(envPath, []byte(envContent), 0644); err != nil {
 		return nil, fmt.Errorf("failed to write .env file: %w", err)
 	}
 
-	// Find exposed port from compose file
-	port := findExposedPort(string(composeContent))
+	return plan, nil
+}
+
+func (s *Service) deployLocal(c *gofr.Context, software string, detail *CatalogDetail, inputs map[string]string, externals []externalService, overrides *resourceOverrides) (interface{}, error) {
+	// Check if Docker is available
+	if err := checkDockerAvailable(); err != nil {
+		return nil, fmt.Errorf("docker is required for local deployment: %w", err)
+	}
+
+	deployDir := s.deploymentDir(software)
+	backup, err := backupDeployFiles(deployDir, filepath.Join(s.catalogPath, software))
+	if err != nil {
+		return nil, err
+	}
+
+	prepared, err := s.prepareDeployment("local", software, detail, inputs, externals, overrides)
+	if err != nil {
+		return nil, s.failDeploy(c, software, deployDir, backup, false, err)
+	}
+	envVars, port := prepared.EnvVars, prepared.Port
 
 	// Run docker-compose up
 	var output strings.Builder
 	output.WriteString(fmt.Sprintf("\n🚀 Deploying %s locally...\n\n", detail.Name))
 
-	cmd := exec.Command("docker", "compose", "-f", composePath, "up", "-d")
-	cmd.Dir = deployDir
+	if warnings := resourceWarnings(detail, prepared); len(warnings) > 0 {
+		output.WriteString("Warnings:\n")
+		for _, w := range warnings {
+			output.WriteString(fmt.Sprintf("  - %s\n", w))
+		}
+		output.WriteString("\n")
+	}
+
+	if len(prepared.Findings) > 0 {
+		output.WriteString("Security audit:\n")
+		writeFindings(&output, "  ", prepared.Findings)
+		output.WriteString("\n")
+	}
+
+	// Pin every image to the digest pulled now, so later restarts and
+	// upgrades run exactly what was deployed
+	images := s.pinnedImages
+	if images != nil {
+		err = writeLockFile(deployDir, images)
+	} else {
+		images, err = lockImages(deployDir, nil, true)
+	}
+	if err != nil {
+		return nil, s.failDeploy(c, software, deployDir, backup, false, err)
+	}
+
+	// One-off hook containers may start dependencies, so roll back as if started
+	if err := runHooks(&output, deployDir, detail, hookPreDeploy); err != nil {
+		return nil, s.failDeploy(c, software, deployDir, backup, true, err)
+	}
+
+	cmd := composeCommand(deployDir, "up", "-d")
 	cmd.Env = append(os.Environ(), envVarsToSlice(envVars)...)
 
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("docker compose failed: %s", stderr.String())
+		return nil, s.failDeploy(c, software, deployDir, backup, true, fmt.Errorf("docker compose failed: %s", strings.TrimSpace(stderr.String())))
+	}
+
+	if err := runHooks(&output, deployDir, detail, hookPostDeploy); err != nil {
+		return nil, s.failDeploy(c, software, deployDir, backup, true, err)
 	}
 
 	// Create deployment record
@@ -75,11 +224,17 @@ func (s *Service) deployLocal(c *gofr.Context, software string, detail *CatalogD
 		Directory: deployDir,
 		Port:      port,
 		Inputs:    inputs,
+		Images:    images,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
+	for _, ext := range externals {
+		deployment.ExternalServices = append(deployment.ExternalServices, ext.Service)
+	}
 
-	s.addDeployment(deployment)
+	if err := s.addDeployment(deployment); err != nil {
+		return nil, s.failDeploy(c, software, deployDir, backup, true, err)
+	}
 
 	output.WriteString("✅ Deployment successful!\n\n")
 	output.WriteString(fmt.Sprintf("  Software: %s\n", detail.Name))
@@ -88,18 +243,19 @@ func (s *Service) deployLocal(c *gofr.Context, software string, detail *CatalogD
 		output.WriteString(fmt.Sprintf("  URL: http://localhost:%d\n", port))
 	}
 	output.WriteString(fmt.Sprintf("  Directory: %s\n", deployDir))
-
-	// Show generated credentials if any
-	if pwd, ok := envVars["DB_PASSWORD"]; ok {
-		output.WriteString(fmt.Sprintf("\n  Generated DB Password: %s\n", pwd))
+	for _, ext := range externals {
+		output.WriteString(fmt.Sprintf("  External %s: %s:%d (replaces '%s')\n", ext.Option, ext.Host, ext.Port, ext.Service))
 	}
-	if pwd, ok := envVars["ADMIN_PASSWORD"]; ok {
-		output.WriteString(fmt.Sprintf("  Generated Admin Password: %s\n", pwd))
+	for _, name := range sortedKeys(prepared.Limits) {
+		output.WriteString(fmt.Sprintf("  Limits for %s: %s\n", name, prepared.Limits[name]))
 	}
-	if pwd, ok := envVars["BASIC_AUTH_PASSWORD"]; ok && inputs["basic_auth_password"] == "" {
-		output.WriteString(fmt.Sprintf("  Generated Auth Password: %s\n", pwd))
+	if len(images) > 0 {
+		output.WriteString(fmt.Sprintf("  Images pinned: %d (%s)\n", len(images), lockFile))
 	}
 
+	// Show generated credentials if any
+	writeGeneratedCredentials(&output, envVars, inputs, prepared.DBPasswordUsed)
+
 	output.WriteString("\nUseful commands:\n")
 	output.WriteString(fmt.Sprintf("  opensourcer logs %s    - View logs\n", software))
 	output.WriteString(fmt.Sprintf("  opensourcer stop %s    - Stop deployment\n", software))
@@ -108,46 +264,178 @@ func (s *Service) deployLocal(c *gofr.Context, software string, detail *CatalogD
 	return output.String(), nil
 }
 
-func (s *Service) getDockerLogs(deployment *LocalDeployment) (interface{}, error) {
-	composePath := filepath.Join(deployment.Directory, "docker-compose.yaml")
+// deployBackup records the files a deploy overwrites in the deployment
+// directory, so a failed deploy can put the previous deployment back
+type deployBackup struct {
+	createdDir bool
 
-	cmd := exec.Command("docker", "compose", "-f", composePath, "logs", "--tail", "100")
-	cmd.Dir = deployment.Directory
+	// files maps a path relative to the directory to its previous content,
+	// nil if the file didn't exist
+	files map[string][]byte
+}
+
+// backupDeployFiles reads the files a deploy of catalogDir into deployDir
+// will write: the catalog files, .env, the compose overrides and the lock
+func backupDeployFiles(deployDir, catalogDir string) (*deployBackup, error) {
+	backup := &deployBackup{createdDir: !pathExists(deployDir), files: make(map[string][]byte)}
+	if backup.createdDir {
+		return backup, nil
+	}
 
-	output, err := cmd.CombinedOutput()
+	names := []string{".env", externalOverrideFile, resourcesOverrideFile, lockFile}
+	err := filepath.Walk(catalogDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(catalogDir, path)
+		names = append(names, rel)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get logs: %w", err)
+		return nil, fmt.Errorf("failed to read catalog files: %w", err)
 	}
 
-	return fmt.Sprintf("\n📋 Logs for %s\n%s\n%s", deployment.Software, strings.Repeat("─", 60), string(output)), nil
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(deployDir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to back up %s: %w", name, err)
+		}
+		backup.files[name] = data
+	}
+	return backup, nil
 }
 
-func (s *Service) stopDocker(deployment *LocalDeployment) (interface{}, error) {
-	composePath := filepath.Join(deployment.Directory, "docker-compose.yaml")
+// restore writes the backed up files back and removes the ones the deploy added
+func (b *deployBackup) restore(deployDir string) error {
+	for _, name := range sortedKeys(b.files) {
+		path := filepath.Join(deployDir, name)
+		data := b.files[name]
+		if data == nil {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	cmd := exec.Command("docker", "compose", "-f", composePath, "stop")
-	cmd.Dir = deployment.Directory
+// failDeploy rolls back a failed deploy unless --keep-on-failure is set: it
+// removes the containers, networks and volumes the compose project created and
+// the deployment directory if this deploy created it. A deploy over an
+// existing directory gets its previous files back instead, and a recorded
+// deployment is brought back up with them. It returns the error to report.
+func (s *Service) failDeploy(c *gofr.Context, software, deployDir string, backup *deployBackup, started bool, err error) error {
+	// Nothing to roll back if the deploy failed before writing any files
+	if backup.createdDir && !started && !pathExists(deployDir) {
+		return err
+	}
+
+	if c.Param("keep-on-failure") == "true" {
+		return fmt.Errorf("%w\n\nDeployment files were kept in %s for debugging", err, deployDir)
+	}
+
+	if backup.createdDir {
+		if started {
+			_ = composeCommand(deployDir, "down", "-v", "--remove-orphans").Run()
+		}
+		_ = os.RemoveAll(deployDir)
+		return fmt.Errorf("%w\n\nThe deployment was rolled back", err)
+	}
+
+	if restoreErr := backup.restore(deployDir); restoreErr != nil {
+		return fmt.Errorf("%w\n\nfailed to restore the previous files in %s: %v", err, deployDir, restoreErr)
+	}
+
+	// Never tear down the project of a deployment that is already recorded;
+	// recreate its containers from the restored files instead
+	existing, findErr := s.findDeployment(software)
+	if started && findErr == nil {
+		if existing == nil {
+			_ = composeCommand(deployDir, "down", "-v", "--remove-orphans").Run()
+		} else if existing.Status == "running" && existing.Target == "local" {
+			if out, upErr := composeCommand(deployDir, "up", "-d").CombinedOutput(); upErr != nil {
+				return fmt.Errorf("%w\n\nThe previous files were restored, but restarting the deployment failed: %s", err, strings.TrimSpace(string(out)))
+			}
+		}
+	}
+
+	return fmt.Errorf("%w\n\nThe deployment was rolled back and the previous files in %s were restored", err, deployDir)
+}
+
+// writeGeneratedCredentials prints passwords opensourcer generated for a
+// deployment. The DB password is skipped when external services made it unused.
+func writeGeneratedCredentials(output *strings.Builder, envVars, inputs map[string]string, dbPasswordUsed bool) {
+	if pwd, ok := envVars["DB_PASSWORD"]; ok && dbPasswordUsed {
+		output.WriteString(fmt.Sprintf("\n  Generated DB Password: %s\n", pwd))
+	}
+	if pwd, ok := envVars["ADMIN_PASSWORD"]; ok {
+		output.WriteString(fmt.Sprintf("  Generated Admin Password: %s\n", pwd))
+	}
+	if pwd, ok := envVars["BASIC_AUTH_PASSWORD"]; ok && inputs["basic_auth_password"] == "" {
+		output.WriteString(fmt.Sprintf("  Generated Auth Password: %s\n", pwd))
+	}
+}
+
+func (s *Service) stopDocker(deployment *LocalDeployment) (interface{}, error) {
+	cmd := composeCommand(deployment.Directory, "stop")
 
 	if err := cmd.Run(); err != nil {
 		return nil, fmt.Errorf("failed to stop containers: %w", err)
 	}
 
-	s.updateDeploymentStatus(deployment.ID, "stopped")
+	if err := s.updateDeploymentStatus(deployment.ID, "stopped"); err != nil {
+		return nil, err
+	}
 
 	return fmt.Sprintf("\n⏹️  Stopped '%s'\n\nUse 'opensourcer start %s' to restart\n", deployment.Software, deployment.Software), nil
 }
 
-func (s *Service) startDocker(deployment *LocalDeployment) (interface{}, error) {
-	composePath := filepath.Join(deployment.Directory, "docker-compose.yaml")
+// startDocker starts the deployment's containers from the images pinned in its
+// lock. With refreshLock it re-resolves the images to their current digests
+// and recreates the containers.
+func (s *Service) startDocker(deployment *LocalDeployment, refreshLock bool) (interface{}, error) {
+	images := deployment.Images
+	if refreshLock {
+		if deployment.Adopted {
+			return nil, fmt.Errorf("adopted deployments have no image lock to refresh")
+		}
+
+		var err error
+		if images, err = lockImages(deployment.Directory, deployment.Images, true); err != nil {
+			return nil, err
+		}
 
-	cmd := exec.Command("docker", "compose", "-f", composePath, "start")
-	cmd.Dir = deployment.Directory
+		var stderr bytes.Buffer
+		cmd := composeCommand(deployment.Directory, "up", "-d")
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("docker compose failed: %s", strings.TrimSpace(stderr.String()))
+		}
+	} else {
+		// The record is the source of truth if the lock file was lost or edited
+		if err := restoreLockFile(deployment); err != nil {
+			return nil, err
+		}
 
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("failed to start containers: %w", err)
+		cmd := composeCommand(deployment.Directory, "start")
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("failed to start containers: %w", err)
+		}
 	}
 
-	s.updateDeploymentStatus(deployment.ID, "running")
+	if err := s.updateDeployment(deployment.ID, func(d *LocalDeployment) {
+		d.Status = "running"
+		d.Images = images
+	}); err != nil {
+		return nil, err
+	}
 
 	var output strings.Builder
 	output.WriteString(fmt.Sprintf("\n✅ Started '%s'\n", deployment.Software))
@@ -155,26 +443,113 @@ func (s *Service) startDocker(deployment *LocalDeployment) (interface{}, error)
 		output.WriteString(fmt.Sprintf("\n  URL: http://localhost:%d\n", deployment.Port))
 	}
 
+	if refreshLock {
+		writeLockChanges(&output, deployment.Images, images)
+	}
+
 	return output.String(), nil
 }
 
-func (s *Service) destroyDocker(deployment *LocalDeployment) (interface{}, error) {
-	composePath := filepath.Join(deployment.Directory, "docker-compose.yaml")
-
-	// Stop and remove containers, networks, volumes
-	cmd := exec.Command("docker", "compose", "-f", composePath, "down", "-v")
-	cmd.Dir = deployment.Directory
+// destroyDocker removes the deployment's containers, networks and volumes. The
+// volumes of an adopted project are kept unless removeVolumes is set, since
+// opensourcer didn't create them.
+func (s *Service) destroyDocker(deployment *LocalDeployment, removeVolumes bool) (interface{}, error) {
+	args := []string{"down"}
+	if !deployment.Adopted || removeVolumes {
+		args = append(args, "-v")
+	}
+	cmd := composeCommand(deployment.Directory, args...)
 
 	if err := cmd.Run(); err != nil {
 		return nil, fmt.Errorf("failed to destroy containers: %w", err)
 	}
 
-	// Remove deployment directory
-	_ = os.RemoveAll(deployment.Directory)
+	// Remove deployment directory, unless it was adopted from an existing project
+	if !deployment.Adopted {
+		_ = os.RemoveAll(deployment.Directory)
+	}
 
 	return nil, nil
 }
 
+// projectStatus reports whether the compose project in dir is "running",
+// "stopped" or "missing" (no containers created). It returns an error when
+// the state can't be read, which says nothing about the containers.
+func projectStatus(dir string) (string, error) {
+	cmd := composeCommand(dir, "ps", "-a", "--format", "{{.State}}")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("docker compose ps failed: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	states := strings.Fields(string(out))
+	if len(states) == 0 {
+		return "missing", nil
+	}
+	for _, state := range states {
+		if state == "running" {
+			return "running", nil
+		}
+	}
+	return "stopped", nil
+}
+
+// composeOverrideFiles are the override files opensourcer may generate in a
+// deployment directory, in the order they are applied
+var composeOverrideFiles = []string{
+	externalOverrideFile,
+	resourcesOverrideFile,
+	lockFile,
+}
+
+// composeCommand builds a docker compose command for a deployment directory,
+// including any generated override files present in it
+func composeCommand(dir string, args ...string) *exec.Cmd {
+	return composeCommandWith(dir, composeOverrideFiles, args...)
+}
+
+// unlockedComposeCommand is composeCommand without the image lock, for
+// resolving the images the project is configured with
+func unlockedComposeCommand(dir string, args ...string) *exec.Cmd {
+	var overrides []string
+	for _, name := range composeOverrideFiles {
+		if name != lockFile {
+			overrides = append(overrides, name)
+		}
+	}
+	return composeCommandWith(dir, overrides, args...)
+}
+
+func composeCommandWith(dir string, overrides []string, args ...string) *exec.Cmd {
+	cmdArgs := []string{"compose", "-f", findComposeFile(dir)}
+	for _, name := range overrides {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			cmdArgs = append(cmdArgs, "-f", filepath.Join(dir, name))
+		}
+	}
+
+	cmd := exec.Command("docker", append(cmdArgs, args...)...)
+	cmd.Dir = dir
+	return cmd
+}
+
+// composeFileNames are the file names docker compose looks for, in order of preference
+var composeFileNames = []string{"docker-compose.yaml", "docker-compose.yml", "compose.yaml", "compose.yml"}
+
+// findComposeFile returns the compose file in dir. Catalog deployments always
+// use docker-compose.yaml; adopted projects may use any of the standard names.
+func findComposeFile(dir string) string {
+	for _, name := range composeFileNames {
+		path := filepath.Join(dir, name)
+		if pathExists(path) {
+			return path
+		}
+	}
+	return filepath.Join(dir, composeFileNames[0])
+}
+
 func checkDockerAvailable() error {
 	cmd := exec.Command("docker", "info")
 	if err := cmd.Run(); err != nil {
@@ -191,39 +566,18 @@ func prepareEnvVars(detail *CatalogDetail, inputs map[string]string) map[string]
 	envVars["ADMIN_PASSWORD"] = generatePassword(12)
 	envVars["SECRET_KEY"] = generatePassword(48)
 
-	// Map input keys to environment variable names
-	keyMapping := map[string]string{
-		"domain":              "DOMAIN",
-		"timezone":            "TIMEZONE",
-		"basic_auth_user":     "BASIC_AUTH_USER",
-		"basic_auth_password": "BASIC_AUTH_PASSWORD",
-		"admin_user":          "ADMIN_USER",
-		"admin_password":      "ADMIN_PASSWORD",
-		"admin_email":         "ADMIN_EMAIL",
-		"site_title":          "SITE_TITLE",
-	}
-
 	// Apply user inputs
 	for key, value := range inputs {
 		if value == "" {
 			continue
 		}
-
-		envKey := strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
-		if mapped, ok := keyMapping[key]; ok {
-			envKey = mapped
-		}
-		envVars[envKey] = value
+		envVars[inputEnvKey(key)] = value
 	}
 
 	// Generate passwords for required fields if not provided
 	if detail.Inputs != nil {
 		for key, input := range detail.Inputs {
-			envKey := strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
-			if mapped, ok := keyMapping[key]; ok {
-				envKey = mapped
-			}
-
+			envKey := inputEnvKey(key)
 			if _, exists := envVars[envKey]; !exists && input.Type == "password" {
 				envVars[envKey] = generatePassword(12)
 			}
@@ -238,14 +592,62 @@ func prepareEnvVars(detail *CatalogDetail, inputs map[string]string) map[string]
 	return envVars
 }
 
+// inputKeyMapping maps input keys to environment variable names
+var inputKeyMapping = map[string]string{
+	"domain":              "DOMAIN",
+	"timezone":            "TIMEZONE",
+	"basic_auth_user":     "BASIC_AUTH_USER",
+	"basic_auth_password": "BASIC_AUTH_PASSWORD",
+	"admin_user":          "ADMIN_USER",
+	"admin_password":      "ADMIN_PASSWORD",
+	"admin_email":         "ADMIN_EMAIL",
+	"site_title":          "SITE_TITLE",
+}
+
+// inputEnvKey returns the environment variable name for an input key
+func inputEnvKey(key string) string {
+	if mapped, ok := inputKeyMapping[key]; ok {
+		return mapped
+	}
+	return strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
 func buildEnvFile(envVars map[string]string) string {
 	var lines []string
-	for key, value := range envVars {
-		lines = append(lines, fmt.Sprintf("%s=%s", key, value))
+	for _, key := range sortedKeys(envVars) {
+		lines = append(lines, fmt.Sprintf("%s=%s", key, envVars[key]))
 	}
 	return strings.Join(lines, "\n")
 }
 
+// parseEnvFile reads KEY=VALUE lines from a .env file, ignoring comments and blank lines
+func parseEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	envVars := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(strings.TrimPrefix(key, "export "))
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		envVars[key] = value
+	}
+
+	return envVars, nil
+}
+
 func envVarsToSlice(envVars map[string]string) []string {
 	var result []string
 	for key, value := range envVars {
@@ -278,6 +680,12 @@ func findExposedPort(composeContent string) int {
 	return 0
 }
 
+// pathExists reports whether a file or directory exists at path
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
 // copyDir copies all files from src directory to dst directory
 func copyDir(src, dst string) error {
 	entries, err := os.ReadDir(src)
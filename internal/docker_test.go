@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanDeploymentInterpolatesPorts(t *testing.T) {
+	s := newTestService(t)
+	writeFile(t, filepath.Join(s.catalogPath, "app", "docker-compose.yaml"), `
+services:
+  web:
+    image: app:1
+    ports:
+      - "${HTTP_PORT:-3000}:${HTTP_PORT:-3000}"
+      - "${ADMIN_PORT}:8081"
+      - "not-a-port"
+`)
+	detail := &CatalogDetail{Name: "App", Services: map[string]ServiceInfo{"web": {}}}
+
+	plan, err := s.planDeployment("local", "app", detail, map[string]string{"http_port": "4000", "admin_port": "9000"}, nil, nil)
+	if err != nil {
+		t.Fatalf("planDeployment: %v", err)
+	}
+
+	ports := plan.Compose.Services["web"].Ports
+	if ports[0].Host != 4000 || ports[0].Container != 4000 {
+		t.Errorf("first port = %+v, want 4000:4000", ports[0])
+	}
+	if ports[1].Host != 9000 || ports[1].Variable {
+		t.Errorf("second port = %+v, want host 9000", ports[1])
+	}
+	if ports[2].Unparsed != "not-a-port" {
+		t.Errorf("third port = %+v, want it left unparsed", ports[2])
+	}
+}
+
+func TestDeployBackupRestore(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing map[string]string
+		deployed map[string]string
+		want     map[string]string
+		missing  []string
+	}{
+		{
+			name: "overwritten files restored",
+			existing: map[string]string{
+				"docker-compose.yaml": "image: app:1",
+				".env":                "DB_PASSWORD=old",
+				"config/app.ini":      "old",
+				lockFile:              "pinned",
+			},
+			deployed: map[string]string{
+				"docker-compose.yaml": "image: app:2",
+				".env":                "DB_PASSWORD=new",
+				"config/app.ini":      "new",
+				lockFile:              "repinned",
+			},
+			want: map[string]string{
+				"docker-compose.yaml": "image: app:1",
+				".env":                "DB_PASSWORD=old",
+				"config/app.ini":      "old",
+				lockFile:              "pinned",
+			},
+		},
+		{
+			name: "added files removed",
+			existing: map[string]string{
+				"docker-compose.yaml": "image: app:1",
+				".env":                "DB_PASSWORD=old",
+			},
+			deployed: map[string]string{
+				"docker-compose.yaml": "image: app:2",
+				"config/app.ini":      "new",
+				externalOverrideFile:  "services: {}",
+				resourcesOverrideFile: "services: {}",
+			},
+			want: map[string]string{
+				"docker-compose.yaml": "image: app:1",
+				".env":                "DB_PASSWORD=old",
+			},
+			missing: []string{"config/app.ini", externalOverrideFile, resourcesOverrideFile},
+		},
+		{
+			name: "unrelated files kept",
+			existing: map[string]string{
+				"docker-compose.yaml": "image: app:1",
+				"data/notes.txt":      "user data",
+			},
+			deployed: map[string]string{
+				"docker-compose.yaml": "image: app:2",
+			},
+			want: map[string]string{
+				"docker-compose.yaml": "image: app:1",
+				"data/notes.txt":      "user data",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			deployDir := filepath.Join(root, "deployment")
+			catalogDir := filepath.Join(root, "catalog")
+			writeFile(t, filepath.Join(catalogDir, "docker-compose.yaml"), "image: app:2")
+			writeFile(t, filepath.Join(catalogDir, "config", "app.ini"), "new")
+			for name, content := range tt.existing {
+				writeFile(t, filepath.Join(deployDir, name), content)
+			}
+
+			backup, err := backupDeployFiles(deployDir, catalogDir)
+			if err != nil {
+				t.Fatalf("backupDeployFiles: %v", err)
+			}
+			if backup.createdDir {
+				t.Fatal("existing directory recorded as created")
+			}
+
+			for name, content := range tt.deployed {
+				writeFile(t, filepath.Join(deployDir, name), content)
+			}
+			if err := backup.restore(deployDir); err != nil {
+				t.Fatalf("restore: %v", err)
+			}
+
+			for name, want := range tt.want {
+				data, err := os.ReadFile(filepath.Join(deployDir, name))
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				if string(data) != want {
+					t.Errorf("%s = %q, want %q", name, data, want)
+				}
+			}
+			for _, name := range tt.missing {
+				if pathExists(filepath.Join(deployDir, name)) {
+					t.Errorf("%s was not removed", name)
+				}
+			}
+		})
+	}
+}
+
+func TestBackupDeployFilesNewDirectory(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "catalog", "docker-compose.yaml"), "image: app:1")
+
+	backup, err := backupDeployFiles(filepath.Join(root, "deployment"), filepath.Join(root, "catalog"))
+	if err != nil {
+		t.Fatalf("backupDeployFiles: %v", err)
+	}
+	if !backup.createdDir || len(backup.files) != 0 {
+		t.Errorf("backup = %+v, want a created directory with no files", backup)
+	}
+}
@@ -0,0 +1,296 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gofr.dev/pkg/gofr"
+)
+
+// Repair actions offered by doctor, each enabled by its own flag or by --fix
+const (
+	repairForget        = "forget"
+	repairSyncStatus    = "sync-status"
+	repairReregister    = "reregister"
+	repairRemoveVolumes = "remove-volumes"
+)
+
+var projectNameInvalidChars = regexp.MustCompile(`[^a-z0-9_-]`)
+
+// driftIssue is a mismatch between deployments.json, deployment directories and Docker
+type driftIssue struct {
+	Subject string
+	Problem string
+	Repair  string
+	apply   func() error
+}
+
+// Doctor cross-checks deployment records, deployment directories, compose
+// projects and volumes, reports drift and optionally repairs it
+func (s *Service) Doctor(c *gofr.Context) (interface{}, error) {
+	deployments, err := s.readDeployments()
+	if err != nil {
+		return nil, fmt.Errorf("%w\n\nRestore deployments.json from its backup before running doctor again", err)
+	}
+
+	dockerErr := checkDockerAvailable()
+
+	var output strings.Builder
+	output.WriteString("\n🩺 Deployment health check\n")
+	output.WriteString(strings.Repeat("-", 60) + "\n\n")
+
+	issues, err := s.diagnose(&output, deployments, dockerErr)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(issues) == 0 {
+		output.WriteString("\nNo problems found.\n")
+		return output.String(), nil
+	}
+
+	fixAll := c.Param("fix") == "true"
+	repaired := 0
+
+	output.WriteString("\n")
+	for _, issue := range issues {
+		output.WriteString(fmt.Sprintf("  ✗ %s: %s\n", issue.Subject, issue.Problem))
+		if issue.Repair == "" {
+			continue
+		}
+		if !fixAll && c.Param(issue.Repair) != "true" {
+			output.WriteString(fmt.Sprintf("      repair with --%s\n", issue.Repair))
+			continue
+		}
+		if err := issue.apply(); err != nil {
+			output.WriteString(fmt.Sprintf("      repair failed: %v\n", err))
+			continue
+		}
+		output.WriteString(fmt.Sprintf("      repaired (%s)\n", issue.Repair))
+		repaired++
+	}
+
+	output.WriteString(fmt.Sprintf("\nFound %d problem(s), repaired %d.\n", len(issues), repaired))
+	if repaired < len(issues) {
+		output.WriteString("Use --fix to apply all repairs, or --forget, --sync-status, --reregister and --remove-volumes individually.\n")
+	}
+
+	return output.String(), nil
+}
+
+// diagnose checks the deployment records against their directories, compose
+// projects and volumes, writing healthy deployments to output and returning
+// the drift found. Docker checks are skipped when dockerErr is set.
+func (s *Service) diagnose(output *strings.Builder, deployments []LocalDeployment, dockerErr error) ([]driftIssue, error) {
+	var issues []driftIssue
+	recorded := make(map[string]bool)
+	deploymentsDir := filepath.Join(s.configPath, "deployments")
+
+	// Compose projects that something still owns, and projects opensourcer
+	// created whose deployment directory is gone. Only the volumes of the
+	// latter are offered for removal, so a hand-run project that happens to
+	// share a name is never touched.
+	projects := make(map[string]bool)
+	orphaned := make(map[string]bool)
+
+	// Records pointing at missing directories or with a stale status
+	for _, d := range deployments {
+		recorded[d.Directory] = true
+
+		if d.Target == "aws" {
+			output.WriteString(fmt.Sprintf("  - %s: AWS deployment, not checked\n", d.Software))
+			continue
+		}
+
+		if !pathExists(d.Directory) {
+			if !d.Adopted && filepath.Dir(d.Directory) == deploymentsDir {
+				orphaned[composeProjectName(d.Directory)] = true
+			}
+			issues = append(issues, driftIssue{
+				Subject: d.Software,
+				Problem: fmt.Sprintf("directory %s no longer exists", d.Directory),
+				Repair:  repairForget,
+				apply:   func() error { return s.removeDeployment(d.ID) },
+			})
+			continue
+		}
+
+		projects[composeProjectName(d.Directory)] = true
+
+		if dockerErr != nil {
+			output.WriteString(fmt.Sprintf("  ✓ %s: directory present\n", d.Software))
+			continue
+		}
+
+		actual, err := projectStatus(d.Directory)
+		if err != nil {
+			issues = append(issues, driftIssue{
+				Subject: d.Software,
+				Problem: fmt.Sprintf("container state can't be read (%v); fix the project in %s by hand", err, d.Directory),
+			})
+			continue
+		}
+		if actual == "missing" {
+			issues = append(issues, driftIssue{
+				Subject: d.Software,
+				Problem: "no containers exist for this deployment",
+				Repair:  repairForget,
+				apply:   func() error { return s.removeDeployment(d.ID) },
+			})
+			continue
+		}
+		if actual != d.Status {
+			issues = append(issues, driftIssue{
+				Subject: d.Software,
+				Problem: fmt.Sprintf("recorded as %s but containers are %s", d.Status, actual),
+				Repair:  repairSyncStatus,
+				apply:   func() error { return s.updateDeploymentStatus(d.ID, actual) },
+			})
+			continue
+		}
+
+		output.WriteString(fmt.Sprintf("  ✓ %s: %s\n", d.Software, d.Status))
+	}
+
+	// Deployment directories without a record
+	entries, _ := os.ReadDir(deploymentsDir)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(deploymentsDir, entry.Name())
+		if recorded[dir] {
+			continue
+		}
+		projects[composeProjectName(dir)] = true
+
+		software := entry.Name()
+		issue := driftIssue{
+			Subject: software,
+			Problem: fmt.Sprintf("directory %s has no deployment record", dir),
+		}
+		if detail, err := s.getCatalogDetail(software); err == nil && pathExists(findComposeFile(dir)) && dockerErr == nil {
+			issue.Repair = repairReregister
+			issue.apply = func() error {
+				_, err := s.registerProject(dir, software, detail, false)
+				return err
+			}
+		} else {
+			issue.Problem += " and can't be re-registered; remove it by hand"
+		}
+		issues = append(issues, issue)
+	}
+
+	if dockerErr != nil {
+		output.WriteString(fmt.Sprintf("\n  Docker checks skipped: %v\n", dockerErr))
+		return issues, nil
+	}
+
+	volumes, err := composeVolumes()
+	if err != nil {
+		return nil, err
+	}
+	withContainers, err := composeProjectsWithContainers()
+	if err != nil {
+		return nil, err
+	}
+	catalogProjects := s.catalogProjectNames()
+
+	for _, v := range volumes {
+		if projects[v.project] {
+			continue
+		}
+		name := v.name
+
+		// Volumes left behind by deployments whose directory was deleted
+		if orphaned[v.project] {
+			issues = append(issues, driftIssue{
+				Subject: v.project,
+				Problem: fmt.Sprintf("volume %s belongs to no deployment", name),
+				Repair:  repairRemoveVolumes,
+				apply: func() error {
+					if out, err := exec.Command("docker", "volume", "rm", name).CombinedOutput(); err != nil {
+						return fmt.Errorf("failed to remove volume %s: %s", name, strings.TrimSpace(string(out)))
+					}
+					return nil
+				},
+			})
+			continue
+		}
+
+		// Volumes named like a catalog entry with no record, directory or
+		// containers, e.g. after deployments.json was edited by hand. A
+		// hand-run project may share the name, so they are only reported.
+		if catalogProjects[v.project] && !withContainers[v.project] {
+			issues = append(issues, driftIssue{
+				Subject: v.project,
+				Problem: fmt.Sprintf("volume %s may be left from a removed deployment; if nothing uses it, remove it with 'docker volume rm %s'", name, name),
+			})
+		}
+	}
+
+	return issues, nil
+}
+
+type composeVolume struct {
+	name    string
+	project string
+}
+
+// composeVolumes lists Docker volumes created by compose projects
+func composeVolumes() ([]composeVolume, error) {
+	cmd := exec.Command("docker", "volume", "ls", "--filter", "label=com.docker.compose.project",
+		"--format", `{{.Name}}	{{.Label "com.docker.compose.project"}}`)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %w", err)
+	}
+
+	var volumes []composeVolume
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		name, project, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		volumes = append(volumes, composeVolume{name: name, project: project})
+	}
+	return volumes, nil
+}
+
+// composeProjectsWithContainers returns the compose projects that have at least one container
+func composeProjectsWithContainers() (map[string]bool, error) {
+	cmd := exec.Command("docker", "ps", "-a", "--filter", "label=com.docker.compose.project",
+		"--format", `{{.Label "com.docker.compose.project"}}`)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	projects := make(map[string]bool)
+	for _, name := range strings.Fields(string(out)) {
+		projects[name] = true
+	}
+	return projects, nil
+}
+
+// catalogProjectNames returns the compose project names deployments of the catalog entries get
+func (s *Service) catalogProjectNames() map[string]bool {
+	names := make(map[string]bool)
+	entries, _ := os.ReadDir(s.catalogPath)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names[composeProjectName(entry.Name())] = true
+		}
+	}
+	return names
+}
+
+// composeProjectName returns the project name docker compose derives from a directory
+func composeProjectName(dir string) string {
+	name := strings.ToLower(filepath.Base(dir))
+	return projectNameInvalidChars.ReplaceAllString(name, "")
+}
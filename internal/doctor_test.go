@@ -0,0 +1,178 @@
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newDoctorDeployment creates a deployment directory with a compose file and,
+// when state is not empty, the container states the fake docker reports for it
+func newDoctorDeployment(t *testing.T, s *Service, name, state string) string {
+	t.Helper()
+	dir := filepath.Join(s.configPath, "deployments", name)
+	writeFile(t, filepath.Join(dir, "docker-compose.yaml"), "services:\n  app:\n    image: app:1\n")
+	if state != "" {
+		writeFile(t, filepath.Join(dir, ".fake-state"), state+"\n")
+	}
+	return dir
+}
+
+func TestDiagnose(t *testing.T) {
+	log := setupFakeDocker(t, "")
+	s := newTestService(t)
+	deploymentsDir := filepath.Join(s.configPath, "deployments")
+
+	for _, entry := range []string{"nextcloud", "jellyfin"} {
+		if err := os.MkdirAll(filepath.Join(s.catalogPath, entry), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deployments := []LocalDeployment{
+		{ID: "1", Software: "gitea", Target: "local", Status: "running", Directory: filepath.Join(deploymentsDir, "gitea-1")},
+		{ID: "2", Software: "wiki", Target: "local", Status: "running", Directory: newDoctorDeployment(t, s, "wiki-1", "exited")},
+		{ID: "3", Software: "blog", Target: "local", Status: "running", Directory: newDoctorDeployment(t, s, "blog-1", "running")},
+		{ID: "4", Software: "gone", Target: "local", Status: "stopped", Directory: newDoctorDeployment(t, s, "gone-1", "")},
+		{ID: "5", Software: "cloud", Target: "aws", Status: "running"},
+	}
+	newDoctorDeployment(t, s, "ghost-1", "")
+
+	t.Setenv("FAKE_DOCKER_VOLUMES", strings.Join([]string{
+		"gitea-1_data\tgitea-1",
+		"wiki-1_data\twiki-1",
+		"nextcloud_data\tnextcloud",
+		"jellyfin_config\tjellyfin",
+		"handrun_data\thandrun",
+	}, "\n")+"\n")
+	t.Setenv("FAKE_DOCKER_CONTAINERS", "jellyfin handrun")
+
+	var output strings.Builder
+	issues, err := s.diagnose(&output, deployments, nil)
+	if err != nil {
+		t.Fatalf("diagnose: %v", err)
+	}
+
+	got := make(map[string]driftIssue)
+	for _, issue := range issues {
+		got[issue.Subject] = issue
+	}
+
+	tests := []struct {
+		subject string
+		repair  string
+		problem string
+	}{
+		{subject: "gitea", repair: repairForget, problem: "no longer exists"},
+		{subject: "wiki", repair: repairSyncStatus, problem: "recorded as running but containers are stopped"},
+		{subject: "gone", repair: repairForget, problem: "no containers exist"},
+		{subject: "ghost-1", problem: "can't be re-registered"},
+		{subject: "gitea-1", repair: repairRemoveVolumes, problem: "volume gitea-1_data belongs to no deployment"},
+		{subject: "nextcloud", problem: "docker volume rm nextcloud_data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			issue, ok := got[tt.subject]
+			if !ok {
+				t.Fatalf("no issue reported, got %+v", issues)
+			}
+			if issue.Repair != tt.repair {
+				t.Errorf("repair = %q, want %q", issue.Repair, tt.repair)
+			}
+			if tt.repair != "" && issue.apply == nil {
+				t.Error("repair has no action")
+			}
+			if !strings.Contains(issue.Problem, tt.problem) {
+				t.Errorf("problem %q does not mention %q", issue.Problem, tt.problem)
+			}
+		})
+	}
+
+	if len(issues) != len(tests) {
+		t.Errorf("got %d issues, want %d: %+v", len(issues), len(tests), issues)
+	}
+	for _, subject := range []string{"blog", "wiki-1", "jellyfin", "handrun"} {
+		if _, ok := got[subject]; ok {
+			t.Errorf("%s reported as drift", subject)
+		}
+	}
+	for _, want := range []string{"✓ blog: running", "cloud: AWS deployment, not checked"} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, output.String())
+		}
+	}
+
+	if err := got["gitea-1"].apply(); err != nil {
+		t.Fatalf("remove volumes: %v", err)
+	}
+	calls := dockerCalls(t, log)
+	if last := calls[len(calls)-1]; last != "volume rm gitea-1_data" {
+		t.Errorf("last docker call = %q, want the volume removed", last)
+	}
+}
+
+func TestDiagnoseRepairs(t *testing.T) {
+	setupFakeDocker(t, "")
+	s := newTestService(t)
+
+	stale := LocalDeployment{ID: "1", Software: "wiki", Target: "local", Status: "running", Directory: newDoctorDeployment(t, s, "wiki-1", "exited")}
+	empty := LocalDeployment{ID: "2", Software: "gone", Target: "local", Status: "running", Directory: newDoctorDeployment(t, s, "gone-1", "")}
+	for _, d := range []LocalDeployment{stale, empty} {
+		if err := s.addDeployment(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var output strings.Builder
+	issues, err := s.diagnose(&output, []LocalDeployment{stale, empty}, nil)
+	if err != nil {
+		t.Fatalf("diagnose: %v", err)
+	}
+	for _, issue := range issues {
+		if err := issue.apply(); err != nil {
+			t.Fatalf("%s: %v", issue.Repair, err)
+		}
+	}
+
+	deployments, err := s.readDeployments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deployments) != 1 || deployments[0].ID != "1" {
+		t.Fatalf("deployments after repair = %+v, want only the synced one", deployments)
+	}
+	if deployments[0].Status != "stopped" {
+		t.Errorf("status = %s, want stopped", deployments[0].Status)
+	}
+}
+
+func TestDiagnoseWithoutDocker(t *testing.T) {
+	log := setupFakeDocker(t, "")
+	s := newTestService(t)
+
+	deployments := []LocalDeployment{
+		{ID: "1", Software: "wiki", Target: "local", Status: "running", Directory: newDoctorDeployment(t, s, "wiki-1", "exited")},
+	}
+	newDoctorDeployment(t, s, "ghost-1", "")
+
+	var output strings.Builder
+	issues, err := s.diagnose(&output, deployments, errors.New("docker is not running"))
+	if err != nil {
+		t.Fatalf("diagnose: %v", err)
+	}
+
+	if len(issues) != 1 || issues[0].Subject != "ghost-1" || issues[0].Repair != "" {
+		t.Errorf("issues = %+v, want only ghost-1 without a repair", issues)
+	}
+	for _, want := range []string{"✓ wiki: directory present", "Docker checks skipped: docker is not running"} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, output.String())
+		}
+	}
+	if calls := dockerCalls(t, log); len(calls) != 0 {
+		t.Errorf("docker called without Docker available: %v", calls)
+	}
+}
@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"gofr.dev/pkg/gofr"
+)
+
+// shellCommand starts bash where the image has it and falls back to sh
+var shellCommand = []string{"sh", "-c", "if command -v bash >/dev/null 2>&1; then exec bash; else exec sh; fi"}
+
+// Exec runs a command in one of the deployment's services
+func (s *Service) Exec(c *gofr.Context) (interface{}, error) {
+	software := getArg(c)
+	command := argsAfterSeparator()
+	if software == "" || len(command) == 0 {
+		return nil, fmt.Errorf("usage: opensourcer exec <software> [--service <name>] -- <command>")
+	}
+
+	return s.execInService(c, software, command)
+}
+
+// Shell opens an interactive shell in one of the deployment's services
+func (s *Service) Shell(c *gofr.Context) (interface{}, error) {
+	software := getArg(c)
+	if software == "" {
+		return nil, fmt.Errorf("usage: opensourcer shell <software> [--service <name>]")
+	}
+
+	return s.execInService(c, software, shellCommand)
+}
+
+func (s *Service) execInService(c *gofr.Context, software string, command []string) (interface{}, error) {
+	deployment, compose, err := s.findLocalProject(software)
+	if err != nil {
+		return nil, err
+	}
+
+	service := getFlagValue(c, "service")
+	if service == "" {
+		detail, _ := s.getCatalogDetail(deployment.Software)
+		service = defaultService(detail, compose)
+	} else if _, ok := compose.Services[service]; !ok {
+		return nil, fmt.Errorf("service '%s' not found. Available services: %s", service, strings.Join(compose.serviceNames(), ", "))
+	}
+
+	if err := composeExec(deployment, service, command); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// findLocalProject loads a local deployment and its compose file
+func (s *Service) findLocalProject(software string) (*LocalDeployment, *ComposeFile, error) {
+	deployment, err := s.findDeployment(software)
+	if err != nil {
+		return nil, nil, err
+	}
+	if deployment == nil {
+		return nil, nil, fmt.Errorf("deployment '%s' not found", software)
+	}
+	if deployment.Target == "aws" {
+		return nil, nil, fmt.Errorf("this command is only supported for local deployments")
+	}
+
+	compose, err := loadComposeFile(findComposeFile(deployment.Directory))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read compose file: %w", err)
+	}
+
+	return deployment, compose, nil
+}
+
+// composeExec runs command in a running service container, attached to the
+// terminal. A TTY is allocated when stdin is one.
+func composeExec(deployment *LocalDeployment, service string, command []string) error {
+	args := []string{"exec"}
+	if !isTerminal(os.Stdin) {
+		args = append(args, "-T")
+	}
+	args = append(args, service)
+
+	cmd := composeCommand(deployment.Directory, append(args, command...)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("command exited with status %d", exitErr.ExitCode())
+		}
+		return fmt.Errorf("failed to exec in %s: %w", service, err)
+	}
+
+	return nil
+}
+
+// defaultService picks the service marked as exposed in the catalog, falling
+// back to the first service that publishes a port
+func defaultService(detail *CatalogDetail, compose *ComposeFile) string {
+	if detail != nil {
+		for _, name := range sortedKeys(detail.Services) {
+			if _, ok := compose.Services[name]; ok && detail.Services[name].Exposed {
+				return name
+			}
+		}
+	}
+
+	names := compose.serviceNames()
+	for _, name := range names {
+		if len(compose.Services[name].Ports) > 0 {
+			return name
+		}
+	}
+	if len(names) > 0 {
+		return names[0]
+	}
+	return ""
+}
+
+// argsAfterSeparator returns the command line arguments following "--"
+func argsAfterSeparator() []string {
+	for i, arg := range os.Args {
+		if arg == "--" {
+			return os.Args[i+1:]
+		}
+	}
+	return nil
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
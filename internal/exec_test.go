@@ -0,0 +1,160 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefaultService(t *testing.T) {
+	compose := parseTestCompose(t, `
+services:
+  db:
+    image: postgres:16
+  web:
+    image: app:1
+    ports:
+      - "3000:3000"
+  worker:
+    image: app:1
+`)
+
+	tests := []struct {
+		name    string
+		detail  *CatalogDetail
+		compose *ComposeFile
+		want    string
+	}{
+		{
+			name:    "exposed in the catalog",
+			detail:  &CatalogDetail{Services: map[string]ServiceInfo{"worker": {Exposed: true}, "web": {}}},
+			compose: compose,
+			want:    "worker",
+		},
+		{
+			name:    "exposed service missing from the project",
+			detail:  &CatalogDetail{Services: map[string]ServiceInfo{"proxy": {Exposed: true}}},
+			compose: compose,
+			want:    "web",
+		},
+		{
+			name:    "no catalog entry",
+			compose: compose,
+			want:    "web",
+		},
+		{
+			name:    "no published ports",
+			compose: parseTestCompose(t, "services:\n  worker:\n    image: app:1\n  cron:\n    image: app:1\n"),
+			want:    "cron",
+		},
+		{
+			name:    "no services",
+			compose: &ComposeFile{},
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := defaultService(tt.detail, tt.compose); got != tt.want {
+				t.Errorf("defaultService = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgsAfterSeparator(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{args: []string{"opensourcer", "exec", "gitea", "--", "ls", "-la"}, want: []string{"ls", "-la"}},
+		{args: []string{"opensourcer", "exec", "gitea", "--", "sh", "-c", "echo -- done"}, want: []string{"sh", "-c", "echo -- done"}},
+		{args: []string{"opensourcer", "exec", "gitea", "--"}, want: []string{}},
+		{args: []string{"opensourcer", "exec", "gitea"}},
+	}
+
+	saved := os.Args
+	defer func() { os.Args = saved }()
+
+	for _, tt := range tests {
+		os.Args = tt.args
+		if got := argsAfterSeparator(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("argsAfterSeparator(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestComposeExec(t *testing.T) {
+	log := setupFakeDocker(t, "")
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "docker-compose.yaml"), "services:\n  web:\n    image: app:1\n")
+	deployment := &LocalDeployment{Software: "app", Directory: dir}
+
+	// Piped input gets no TTY
+	stdin, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	defer stdin.Close()
+	saved := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = saved }()
+
+	if err := composeExec(deployment, "web", []string{"ls", "/data"}); err != nil {
+		t.Fatalf("composeExec: %v", err)
+	}
+	err = composeExec(deployment, "web", []string{"FAIL"})
+	if err == nil || !strings.Contains(err.Error(), "exited with status 1") {
+		t.Errorf("error = %v, want the exit status", err)
+	}
+
+	want := []string{"compose exec -T web ls /data", "compose exec -T web FAIL"}
+	if got := dockerCalls(t, log); !reflect.DeepEqual(got, want) {
+		t.Errorf("docker calls = %v, want %v", got, want)
+	}
+}
+
+func TestFindLocalProject(t *testing.T) {
+	s := newTestService(t)
+	dir := filepath.Join(s.configPath, "deployments", "app")
+	writeFile(t, filepath.Join(dir, "docker-compose.yaml"), "services:\n  web:\n    image: app:1\n")
+	for _, d := range []LocalDeployment{
+		{ID: "1", Software: "app", Target: "local", Directory: dir},
+		{ID: "2", Software: "cloud", Target: "aws"},
+	} {
+		if err := s.addDeployment(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		software string
+		wantErr  string
+	}{
+		{software: "app"},
+		{software: "cloud", wantErr: "only supported for local deployments"},
+		{software: "missing", wantErr: "deployment 'missing' not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.software, func(t *testing.T) {
+			deployment, compose, err := s.findLocalProject(tt.software)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("findLocalProject: %v", err)
+			}
+			if deployment.ID != "1" || compose.Services["web"].Image != "app:1" {
+				t.Errorf("got %+v with %+v", deployment, compose)
+			}
+		})
+	}
+}
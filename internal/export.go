@@ -0,0 +1,725 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gofr.dev/pkg/gofr"
+	"gopkg.in/yaml.v3"
+)
+
+// Placeholders substituted after marshalling, so the same manifests can be
+// rendered as plain YAML or as Helm templates
+const (
+	exportNamePrefix  = "__PREFIX__"
+	exportStorageSize = "__STORAGE__"
+	defaultVolumeSize = "1Gi"
+)
+
+// wellKnownPorts maps image names to the port they listen on, for services
+// that don't publish or expose a port in the compose file
+var wellKnownPorts = map[string]int{
+	"postgres":   5432,
+	"mysql":      3306,
+	"mariadb":    3306,
+	"redis":      6379,
+	"valkey":     6379,
+	"mongo":      27017,
+	"clickhouse": 8123,
+	"memcached":  11211,
+}
+
+var helmIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Export writes a catalog entry as Kubernetes manifests, a Helm chart or a Kustomize base
+func (s *Service) Export(c *gofr.Context) (interface{}, error) {
+	software := getArg(c)
+	if software == "" {
+		return nil, fmt.Errorf("usage: opensourcer export <software> [--format=manifests|helm|kustomize] [--output=<dir>]")
+	}
+
+	detail, err := s.getCatalogDetail(software)
+	if err != nil {
+		return nil, err
+	}
+
+	compose, err := loadComposeFile(s.getComposePath(software))
+	if err != nil {
+		return nil, fmt.Errorf("docker-compose.yaml not found for '%s'", software)
+	}
+
+	format := c.Param("format")
+	if format == "" {
+		format = "manifests"
+	}
+
+	outDir := c.Param("output")
+	if outDir == "" {
+		outDir = fmt.Sprintf("%s-%s", software, format)
+	}
+
+	e := &k8sExport{
+		slug:       software,
+		detail:     detail,
+		compose:    compose,
+		composeDir: filepath.Join(s.catalogPath, software),
+	}
+
+	var files map[string]string
+	switch format {
+	case "manifests":
+		files, err = e.manifests(prepareEnvVars(detail, parseInputs(c, detail)))
+	case "helm":
+		files, err = e.helmChart()
+	case "kustomize":
+		files, err = e.kustomizeBase(prepareEnvVars(detail, parseInputs(c, detail)))
+	default:
+		return nil, fmt.Errorf("unknown format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeFiles(outDir, files); err != nil {
+		return nil, fmt.Errorf("failed to write export: %w", err)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("\n✅ Exported %s as %s to %s\n\n", detail.Name, format, outDir))
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		output.WriteString(fmt.Sprintf("  %s\n", name))
+	}
+
+	if len(e.warnings) > 0 {
+		output.WriteString("\nWarnings:\n")
+		for _, w := range e.warnings {
+			output.WriteString(fmt.Sprintf("  - %s\n", w))
+		}
+	}
+
+	return output.String(), nil
+}
+
+// k8sExport converts a catalog compose file into Kubernetes objects
+type k8sExport struct {
+	slug       string
+	detail     *CatalogDetail
+	compose    *ComposeFile
+	composeDir string
+	secretKeys map[string]bool
+	warnings   []string
+}
+
+func (e *k8sExport) manifests(envVars map[string]string) (map[string]string, error) {
+	e.secretKeys = keySet(envVars)
+
+	objects, err := e.workloads()
+	if err != nil {
+		return nil, err
+	}
+
+	secret := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   e.metadata(exportNamePrefix+"-env", ""),
+		"type":       "Opaque",
+		"stringData": envVars,
+	}
+	objects = append([]map[string]interface{}{secret}, objects...)
+
+	content, err := marshalDocuments(objects)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		e.slug + ".yaml": e.render(content, e.slug, defaultVolumeSize),
+	}, nil
+}
+
+func (e *k8sExport) kustomizeBase(envVars map[string]string) (map[string]string, error) {
+	e.secretKeys = keySet(envVars)
+
+	objects, err := e.workloads()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)
+	var resources []string
+	for _, obj := range objects {
+		name := fmt.Sprintf("%s-%s.yaml", strings.ToLower(obj["kind"].(string)), objectName(obj))
+		content, err := marshalDocuments([]map[string]interface{}{obj})
+		if err != nil {
+			return nil, err
+		}
+		files[name] = e.render(content, e.slug, defaultVolumeSize)
+		resources = append(resources, name)
+	}
+	sort.Strings(resources)
+
+	kustomization := map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"labels": []interface{}{
+			map[string]interface{}{
+				"pairs":            map[string]string{"app.kubernetes.io/part-of": e.slug},
+				"includeSelectors": false,
+			},
+		},
+		"resources": resources,
+		"secretGenerator": []interface{}{
+			map[string]interface{}{
+				"name": e.slug + "-env",
+				"envs": []string{"secret.env"},
+			},
+		},
+	}
+
+	content, err := marshalDocuments([]map[string]interface{}{kustomization})
+	if err != nil {
+		return nil, err
+	}
+
+	files["kustomization.yaml"] = content
+	files["secret.env"] = buildEnvFile(envVars) + "\n"
+
+	return files, nil
+}
+
+func (e *k8sExport) helmChart() (map[string]string, error) {
+	envKeys := prepareEnvVars(e.detail, nil)
+	for key := range e.detail.Inputs {
+		envKeys[inputEnvKey(key)] = ""
+	}
+	e.secretKeys = keySet(envKeys)
+
+	objects, err := e.workloads()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)
+	for _, obj := range objects {
+		content, err := marshalDocuments([]map[string]interface{}{obj})
+		if err != nil {
+			return nil, err
+		}
+		name := fmt.Sprintf("templates/%s-%s.yaml", strings.ToLower(obj["kind"].(string)), objectName(obj))
+		files[name] = e.render(content, "{{ .Release.Name }}", "{{ .Values.persistence.size }}")
+	}
+
+	chart := struct {
+		APIVersion  string   `yaml:"apiVersion"`
+		Name        string   `yaml:"name"`
+		Description string   `yaml:"description"`
+		Type        string   `yaml:"type"`
+		Version     string   `yaml:"version"`
+		Home        string   `yaml:"home,omitempty"`
+		Icon        string   `yaml:"icon,omitempty"`
+		Keywords    []string `yaml:"keywords,omitempty"`
+	}{
+		APIVersion:  "v2",
+		Name:        e.slug,
+		Description: e.detail.Description,
+		Type:        "application",
+		Version:     "0.1.0",
+		Home:        e.detail.Website,
+		Icon:        e.detail.Icon,
+		Keywords:    e.detail.Tags,
+	}
+	chartYAML, err := yaml.Marshal(chart)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := e.helmSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	files["Chart.yaml"] = string(chartYAML)
+	files["values.yaml"] = e.helmValues(envKeys)
+	files["values.schema.json"] = schema
+	files["templates/secret.yaml"] = e.helmSecret(envKeys)
+
+	return files, nil
+}
+
+// helmValues renders values.yaml with one entry per catalog input
+func (e *k8sExport) helmValues(envKeys map[string]string) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# Default values for %s.\n\n", e.slug))
+
+	for _, key := range sortedKeys(e.detail.Inputs) {
+		input := e.detail.Inputs[key]
+		comment := input.Label
+		if input.Required {
+			comment += " (required)"
+		}
+		if input.Description != "" {
+			comment += " - " + input.Description
+		}
+		value := input.Default
+		if value == "" && inputEnvKey(key) == "DOMAIN" {
+			value = "localhost"
+		}
+		b.WriteString(fmt.Sprintf("# %s\n%s: %q\n\n", comment, key, value))
+	}
+
+	b.WriteString("# Generated secrets. Leave empty to generate a random value on install; upgrades keep the installed value.\nsecrets:\n")
+	for _, key := range e.generatedSecretKeys(envKeys) {
+		b.WriteString(fmt.Sprintf("  %s: \"\"\n", key))
+	}
+
+	b.WriteString(fmt.Sprintf("\npersistence:\n  size: %s\n", defaultVolumeSize))
+
+	return b.String()
+}
+
+// helmSchema renders values.schema.json from the catalog inputs
+func (e *k8sExport) helmSchema() (string, error) {
+	properties := make(map[string]interface{})
+	required := []string{}
+
+	for _, key := range sortedKeys(e.detail.Inputs) {
+		input := e.detail.Inputs[key]
+		prop := map[string]interface{}{
+			"type":  "string",
+			"title": input.Label,
+		}
+		if input.Description != "" {
+			prop["description"] = input.Description
+		}
+		if input.Default != "" {
+			prop["default"] = input.Default
+		}
+		if input.Type == "password" {
+			prop["format"] = "password"
+		}
+		if input.Required {
+			prop["minLength"] = 1
+			required = append(required, key)
+		}
+		properties[key] = prop
+	}
+
+	properties["secrets"] = map[string]interface{}{"type": "object"}
+	properties["persistence"] = map[string]interface{}{"type": "object"}
+
+	schema := map[string]interface{}{
+		"$schema":    "https://json-schema.org/draft-07/schema#",
+		"type":       "object",
+		"required":   required,
+		"properties": properties,
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// helmSecret renders the env Secret template, wiring inputs and generated secrets to values
+func (e *k8sExport) helmSecret(envKeys map[string]string) string {
+	inputFor := make(map[string]string)
+	for key := range e.detail.Inputs {
+		inputFor[inputEnvKey(key)] = key
+	}
+
+	// Generated values are looked up in the installed Secret first, so an
+	// upgrade keeps the passwords the databases were initialised with
+	var b strings.Builder
+	b.WriteString("{{- $existing := dict }}\n")
+	b.WriteString("{{- with (lookup \"v1\" \"Secret\" .Release.Namespace (printf \"%s-env\" .Release.Name)) }}\n")
+	b.WriteString("{{- $existing = .data | default dict }}\n")
+	b.WriteString("{{- end }}\n")
+	b.WriteString("apiVersion: v1\nkind: Secret\nmetadata:\n  name: {{ .Release.Name }}-env\n")
+	b.WriteString(fmt.Sprintf("  labels:\n    app.kubernetes.io/name: %s\n    app.kubernetes.io/instance: {{ .Release.Name }}\n", e.slug))
+	b.WriteString("type: Opaque\nstringData:\n")
+
+	for _, envKey := range sortedKeys(envKeys) {
+		if key, ok := inputFor[envKey]; ok {
+			ref := helmValueRef(key)
+			if e.detail.Inputs[key].Type == "password" {
+				b.WriteString(fmt.Sprintf("  %s: {{ %s | default (get $existing %q | b64dec) | default (randAlphaNum 16) | quote }}\n", envKey, ref, envKey))
+			} else {
+				b.WriteString(fmt.Sprintf("  %s: {{ %s | quote }}\n", envKey, ref))
+			}
+			continue
+		}
+
+		if envKey == "DOMAIN" {
+			b.WriteString("  DOMAIN: \"localhost\"\n")
+			continue
+		}
+
+		b.WriteString(fmt.Sprintf("  %s: {{ .Values.secrets.%s | default (get $existing %q | b64dec) | default (randAlphaNum %d) | quote }}\n", envKey, envKey, envKey, len(envKeys[envKey])))
+	}
+
+	return b.String()
+}
+
+// generatedSecretKeys returns env variables that are generated rather than backed by an input
+func (e *k8sExport) generatedSecretKeys(envKeys map[string]string) []string {
+	fromInput := make(map[string]bool)
+	for key := range e.detail.Inputs {
+		fromInput[inputEnvKey(key)] = true
+	}
+
+	var keys []string
+	for _, key := range sortedKeys(envKeys) {
+		if !fromInput[key] && key != "DOMAIN" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// workloads converts every compose service into a Deployment, an optional
+// Service and the PersistentVolumeClaims and ConfigMaps it mounts
+func (e *k8sExport) workloads() ([]map[string]interface{}, error) {
+	var objects []map[string]interface{}
+	claims := make(map[string]bool)
+
+	for _, name := range e.compose.serviceNames() {
+		svc := e.compose.Services[name]
+		if svc.Image == "" {
+			e.warnings = append(e.warnings, fmt.Sprintf("service '%s' has no image and was skipped", name))
+			continue
+		}
+
+		container := map[string]interface{}{
+			"name":  name,
+			"image": svc.Image,
+		}
+		if len(svc.Command) > 0 {
+			container["args"] = []string(svc.Command)
+		}
+		if env := e.containerEnv(svc.Environment); len(env) > 0 {
+			container["env"] = env
+		}
+
+		ports := e.containerPorts(svc)
+		if len(ports) > 0 {
+			var containerPorts []interface{}
+			for _, p := range ports {
+				containerPorts = append(containerPorts, map[string]interface{}{"containerPort": p})
+			}
+			container["ports"] = containerPorts
+		}
+
+		var mounts, volumes []interface{}
+		files := make(map[string]string)
+		for i, v := range svc.Volumes {
+			volName := fmt.Sprintf("data-%d", i)
+			switch {
+			case e.compose.isNamedVolume(v.Source):
+				claim := fmt.Sprintf("%s-%s", exportNamePrefix, v.Source)
+				claims[v.Source] = true
+				volumes = append(volumes, map[string]interface{}{
+					"name":                  volName,
+					"persistentVolumeClaim": map[string]interface{}{"claimName": claim},
+				})
+				mount := map[string]interface{}{"name": volName, "mountPath": v.Target}
+				if v.ReadOnly {
+					mount["readOnly"] = true
+				}
+				mounts = append(mounts, mount)
+			case v.Source == "":
+				volumes = append(volumes, map[string]interface{}{"name": volName, "emptyDir": map[string]interface{}{}})
+				mounts = append(mounts, map[string]interface{}{"name": volName, "mountPath": v.Target})
+			case strings.HasPrefix(v.Source, "."):
+				srcPath := resolveBindSource(e.composeDir, v.Source)
+				info, err := os.Stat(srcPath)
+				if err != nil || info.IsDir() {
+					e.warnings = append(e.warnings, fmt.Sprintf("service '%s': directory bind mount %s was replaced with an emptyDir", name, v.Source))
+					volumes = append(volumes, map[string]interface{}{"name": volName, "emptyDir": map[string]interface{}{}})
+					mounts = append(mounts, map[string]interface{}{"name": volName, "mountPath": v.Target})
+					continue
+				}
+				data, err := os.ReadFile(srcPath)
+				if err != nil {
+					return nil, err
+				}
+				key := configMapKey(v.Source)
+				files[key] = string(data)
+				mounts = append(mounts, map[string]interface{}{"name": "files", "mountPath": v.Target, "subPath": key, "readOnly": true})
+			default:
+				e.warnings = append(e.warnings, fmt.Sprintf("service '%s': host mount %s is not supported and was skipped", name, v.Source))
+			}
+		}
+
+		if len(files) > 0 {
+			configMap := fmt.Sprintf("%s-%s-files", exportNamePrefix, name)
+			volumes = append(volumes, map[string]interface{}{
+				"name":      "files",
+				"configMap": map[string]interface{}{"name": configMap},
+			})
+			objects = append(objects, map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   e.metadata(configMap, name),
+				"data":       files,
+			})
+		}
+		if len(mounts) > 0 {
+			container["volumeMounts"] = mounts
+		}
+
+		podSpec := map[string]interface{}{
+			"containers": []interface{}{container},
+		}
+		if len(volumes) > 0 {
+			podSpec["volumes"] = volumes
+		}
+
+		deploySpec := map[string]interface{}{
+			"replicas": 1,
+			"selector": map[string]interface{}{"matchLabels": e.labels(name)},
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": e.labels(name)},
+				"spec":     podSpec,
+			},
+		}
+		if len(svc.Volumes) > 0 {
+			// ReadWriteOnce claims can't be attached to two pods during a rolling update
+			deploySpec["strategy"] = map[string]interface{}{"type": "Recreate"}
+		}
+
+		objects = append(objects, map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   e.metadata(fmt.Sprintf("%s-%s", exportNamePrefix, name), name),
+			"spec":       deploySpec,
+		})
+
+		if len(ports) > 0 {
+			// The Service keeps the compose service name so containers can
+			// keep reaching each other by the hostnames used in the compose file
+			var servicePorts []interface{}
+			for _, p := range ports {
+				servicePorts = append(servicePorts, map[string]interface{}{
+					"name":       fmt.Sprintf("port-%d", p),
+					"port":       p,
+					"targetPort": p,
+				})
+			}
+			objects = append(objects, map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata":   e.metadata(name, name),
+				"spec": map[string]interface{}{
+					"selector": e.labels(name),
+					"ports":    servicePorts,
+				},
+			})
+		}
+	}
+
+	for _, claim := range sortedKeys(claims) {
+		objects = append(objects, map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "PersistentVolumeClaim",
+			"metadata":   e.metadata(fmt.Sprintf("%s-%s", exportNamePrefix, claim), ""),
+			"spec": map[string]interface{}{
+				"accessModes": []string{"ReadWriteOnce"},
+				"resources": map[string]interface{}{
+					"requests": map[string]interface{}{"storage": exportStorageSize},
+				},
+			},
+		})
+	}
+
+	return objects, nil
+}
+
+// containerEnv converts compose environment entries into container env vars.
+// Variables that resolve to the env Secret are read from it, and values that
+// embed them use Kubernetes' $(VAR) dependent variable expansion.
+func (e *k8sExport) containerEnv(env composeEnv) []interface{} {
+	var refs, plain []interface{}
+	seen := make(map[string]bool)
+
+	secretRef := func(name, key string) map[string]interface{} {
+		return map[string]interface{}{
+			"name": name,
+			"valueFrom": map[string]interface{}{
+				"secretKeyRef": map[string]interface{}{"name": exportNamePrefix + "-env", "key": key},
+			},
+		}
+	}
+
+	for _, key := range sortedKeys(env) {
+		value := env[key]
+
+		if loc := composeVarPattern.FindStringSubmatchIndex(value); loc != nil && loc[0] == 0 && loc[1] == len(value) {
+			m := composeVarPattern.FindStringSubmatch(value)
+			name := m[1]
+			if name == "" {
+				name = m[3]
+			}
+			if e.secretKeys[name] {
+				plain = append(plain, secretRef(key, name))
+			} else {
+				plain = append(plain, map[string]interface{}{"name": key, "value": m[2]})
+			}
+			seen[key] = true
+			continue
+		}
+
+		value = composeVarPattern.ReplaceAllStringFunc(value, func(ref string) string {
+			m := composeVarPattern.FindStringSubmatch(ref)
+			name := m[1]
+			if name == "" {
+				name = m[3]
+			}
+			if !e.secretKeys[name] {
+				return m[2]
+			}
+			if !seen[name] {
+				refs = append(refs, secretRef(name, name))
+				seen[name] = true
+			}
+			return fmt.Sprintf("$(%s)", name)
+		})
+		plain = append(plain, map[string]interface{}{"name": key, "value": value})
+		seen[key] = true
+	}
+
+	return append(refs, plain...)
+}
+
+// containerPorts returns the container ports a service listens on
+func (e *k8sExport) containerPorts(svc ComposeService) []int {
+	seen := make(map[int]bool)
+	var ports []int
+
+	add := func(p ComposePort) {
+		for port := p.Container; port > 0 && port < p.Container+max(p.Count, 1); port++ {
+			if !seen[port] {
+				seen[port] = true
+				ports = append(ports, port)
+			}
+		}
+	}
+	for _, p := range svc.Ports {
+		add(p)
+	}
+	for _, spec := range svc.Expose {
+		if p, err := parsePortSpec(spec); err == nil {
+			add(p)
+		}
+	}
+
+	if len(ports) == 0 {
+		if port, ok := wellKnownPorts[imageName(svc.Image)]; ok {
+			ports = append(ports, port)
+		}
+	}
+
+	return ports
+}
+
+func (e *k8sExport) labels(component string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":      e.slug,
+		"app.kubernetes.io/instance":  exportNamePrefix,
+		"app.kubernetes.io/component": component,
+	}
+}
+
+func (e *k8sExport) metadata(name, component string) map[string]interface{} {
+	labels := map[string]string{
+		"app.kubernetes.io/name":     e.slug,
+		"app.kubernetes.io/instance": exportNamePrefix,
+	}
+	if component != "" {
+		labels["app.kubernetes.io/component"] = component
+	}
+	return map[string]interface{}{"name": name, "labels": labels}
+}
+
+// render substitutes the name prefix and storage size placeholders
+func (e *k8sExport) render(content, prefix, storage string) string {
+	content = strings.ReplaceAll(content, exportNamePrefix, prefix)
+	return strings.ReplaceAll(content, exportStorageSize, storage)
+}
+
+func marshalDocuments(objects []map[string]interface{}) (string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, obj := range objects {
+		if err := enc.Encode(obj); err != nil {
+			return "", err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func objectName(obj map[string]interface{}) string {
+	name := obj["metadata"].(map[string]interface{})["name"].(string)
+	return strings.TrimPrefix(name, exportNamePrefix+"-")
+}
+
+// configMapKey turns a bind mount source path into a valid ConfigMap key
+func configMapKey(source string) string {
+	key := strings.TrimLeft(filepath.ToSlash(filepath.Clean(source)), "./")
+	return strings.ReplaceAll(key, "/", "_")
+}
+
+// helmValueRef returns the template expression for a top-level value
+func helmValueRef(key string) string {
+	if helmIdentifier.MatchString(key) {
+		return ".Values." + key
+	}
+	return fmt.Sprintf("(index .Values %q)", key)
+}
+
+// writeFiles writes a set of relative paths and their contents below dir
+func writeFiles(dir string, files map[string]string) error {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func keySet(m map[string]string) map[string]bool {
+	set := make(map[string]bool, len(m))
+	for key := range m {
+		set[key] = true
+	}
+	return set
+}
@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Lifecycle hooks a catalog entry can declare in app.json
+const (
+	hookPreDeploy   = "pre-deploy"
+	hookPostDeploy  = "post-deploy"
+	hookPreUpgrade  = "pre-upgrade"
+	hookPostUpgrade = "post-upgrade"
+	hookPreDestroy  = "pre-destroy"
+)
+
+// runHooks runs the steps of a lifecycle hook in order, writing their output
+// to output. A step runs in its service's container when that is running and
+// in a one-off container otherwise. The first failing step stops the hook.
+func runHooks(output *strings.Builder, dir string, detail *CatalogDetail, hook string) error {
+	steps := detail.Hooks[hook]
+	if len(steps) == 0 {
+		return nil
+	}
+
+	compose, err := loadComposeFile(findComposeFile(dir))
+	if err != nil {
+		return fmt.Errorf("%s hook: %w", hook, err)
+	}
+
+	vars, _ := parseEnvFile(filepath.Join(dir, ".env"))
+	running := runningServices(dir)
+
+	for i, step := range steps {
+		service := step.Service
+		if service == "" {
+			service = defaultService(detail, compose)
+		}
+		if _, ok := compose.Services[service]; !ok {
+			return fmt.Errorf("%s hook: service '%s' not found", hook, service)
+		}
+
+		// Log the command before interpolation so secrets from .env aren't printed
+		output.WriteString(fmt.Sprintf("🪝 %s (%d/%d) in %s: %s\n", hook, i+1, len(steps), service, strings.Join(step.Command, " ")))
+
+		command := make([]string, len(step.Command))
+		for j, arg := range step.Command {
+			command[j] = interpolateCompose(arg, vars)
+		}
+
+		args := []string{"run", "--rm", service}
+		if running[service] {
+			args = []string{"exec", "-T", service}
+		}
+
+		out, err := composeCommand(dir, append(args, command...)...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%s hook failed in %s: %s", hook, service, strings.TrimSpace(string(out)))
+		}
+		for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+			if line != "" {
+				output.WriteString("   " + line + "\n")
+			}
+		}
+	}
+	output.WriteString("\n")
+
+	return nil
+}
+
+// runDeploymentHooks runs a lifecycle hook for a recorded deployment. Adopted
+// projects weren't created from the catalog, so its hooks don't apply to them.
+// Without a readable catalog entry the hooks are skipped with a warning.
+func (s *Service) runDeploymentHooks(output *strings.Builder, deployment *LocalDeployment, hook string) error {
+	if deployment.Adopted {
+		return nil
+	}
+
+	detail, err := s.getCatalogDetail(deployment.Software)
+	if err != nil {
+		output.WriteString(fmt.Sprintf("⚠️  Skipped the %s hooks: %v\n\n", hook, err))
+		return nil
+	}
+
+	return runHooks(output, deployment.Directory, detail, hook)
+}
+
+// runningServices returns the services of the compose project in dir that have a running container
+func runningServices(dir string) map[string]bool {
+	running := make(map[string]bool)
+
+	out, err := composeCommand(dir, "ps", "--status", "running", "--services").Output()
+	if err != nil {
+		return running
+	}
+	for _, name := range strings.Fields(string(out)) {
+		running[name] = true
+	}
+	return running
+}
@@ -0,0 +1,200 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeDocker is a stand-in for the docker CLI. It logs every call, lists the
+// services in FAKE_DOCKER_RUNNING as running and fails commands containing FAIL.
+// Compose container states and IDs come from .fake-state and .fake-ps files in
+// the project directory, and volume, container, stats and disk usage listings
+// from FAKE_DOCKER_VOLUMES, FAKE_DOCKER_CONTAINERS, FAKE_DOCKER_STATS and
+// FAKE_DOCKER_DF. Image repo digests are read from FAKE_DOCKER_DIGESTS.
+const fakeDocker = `#!/bin/sh
+echo "$*" >> "$FAKE_DOCKER_LOG"
+case "$*" in
+  *FAIL*) echo "step failed"; exit 1 ;;
+  *" ps --status running --services") printf '%s\n' $FAKE_DOCKER_RUNNING ;;
+  *" ps -a --format {{.State}}") cat .fake-state 2>/dev/null || true ;;
+  *" ps -a --format {{.ID}}"*) cat .fake-ps 2>/dev/null || true ;;
+  "volume ls "*) printf '%b' "$FAKE_DOCKER_VOLUMES" ;;
+  "ps -a "*) printf '%s\n' $FAKE_DOCKER_CONTAINERS ;;
+  "stats "*) printf '%b' "$FAKE_DOCKER_STATS" ;;
+  "system df "*) printf '%s\n' "$FAKE_DOCKER_DF" ;;
+  "image inspect --format {{json .RepoDigests}} "*) printf '%s\n' "$FAKE_DOCKER_DIGESTS" ;;
+  *) echo "ok" ;;
+esac
+`
+
+// setupFakeDocker puts the fake docker CLI first on PATH and returns its call log
+func setupFakeDocker(t *testing.T, running string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake docker CLI is a shell script")
+	}
+
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "docker"), []byte(fakeDocker), 0755); err != nil {
+		t.Fatal(err)
+	}
+	log := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("FAKE_DOCKER_LOG", log)
+	t.Setenv("FAKE_DOCKER_RUNNING", running)
+	return log
+}
+
+// dockerCalls returns the logged docker calls with the compose -f arguments removed
+func dockerCalls(t *testing.T, log string) []string {
+	t.Helper()
+	data, err := os.ReadFile(log)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		fields := strings.Fields(line)
+		var kept []string
+		for i := 0; i < len(fields); i++ {
+			if fields[i] == "-f" {
+				i++
+				continue
+			}
+			kept = append(kept, fields[i])
+		}
+		calls = append(calls, strings.Join(kept, " "))
+	}
+	return calls
+}
+
+func TestRunHooks(t *testing.T) {
+	detail := &CatalogDetail{
+		Services: map[string]ServiceInfo{"web": {Exposed: true}, "db": {}},
+		Hooks: map[string][]HookStep{
+			hookPostDeploy: {
+				{Command: []string{"migrate", "--password", "${DB_PASSWORD}"}},
+				{Service: "db", Command: []string{"psql", "-c", "select 1"}},
+			},
+			hookPreDestroy: {
+				{Command: []string{"backup"}},
+				{Command: []string{"FAIL"}},
+				{Command: []string{"never-run"}},
+			},
+			hookPreUpgrade: {{Service: "cache", Command: []string{"flush"}}},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		hook       string
+		running    string
+		wantCalls  []string
+		wantErr    string
+		wantOutput []string
+	}{
+		{
+			name:      "no steps",
+			hook:      hookPreDeploy,
+			wantCalls: nil,
+		},
+		{
+			name:    "steps run in order, exec in running services",
+			hook:    hookPostDeploy,
+			running: "web",
+			wantCalls: []string{
+				"compose ps --status running --services",
+				"compose exec -T web migrate --password s3cret",
+				"compose run --rm db psql -c select 1",
+			},
+			wantOutput: []string{"post-deploy (1/2) in web: migrate --password ${DB_PASSWORD}", "post-deploy (2/2) in db"},
+		},
+		{
+			name:    "first failing step stops the hook",
+			hook:    hookPreDestroy,
+			running: "web",
+			wantCalls: []string{
+				"compose ps --status running --services",
+				"compose exec -T web backup",
+				"compose exec -T web FAIL",
+			},
+			wantErr: "pre-destroy hook failed in web: step failed",
+		},
+		{
+			name:    "unknown service",
+			hook:    hookPreUpgrade,
+			wantErr: "service 'cache' not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := setupFakeDocker(t, tt.running)
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "docker-compose.yaml"), "services:\n  web:\n    image: app:1\n  db:\n    image: postgres:16\n")
+			writeFile(t, filepath.Join(dir, ".env"), "DB_PASSWORD=s3cret\n")
+
+			var output strings.Builder
+			err := runHooks(&output, dir, detail, tt.hook)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("runHooks: %v", err)
+			}
+
+			if calls := dockerCalls(t, log); tt.wantCalls != nil && strings.Join(calls, "\n") != strings.Join(tt.wantCalls, "\n") {
+				t.Errorf("calls = %q, want %q", calls, tt.wantCalls)
+			}
+			if strings.Contains(output.String(), "s3cret") {
+				t.Errorf("output shows a secret: %q", output.String())
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(output.String(), want) {
+					t.Errorf("output = %q, want it to contain %q", output.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunDeploymentHooks(t *testing.T) {
+	log := setupFakeDocker(t, "")
+	s := newTestService(t)
+	dir := filepath.Join(s.configPath, "deployments", "app")
+	writeFile(t, filepath.Join(dir, "docker-compose.yaml"), "services:\n  web:\n    image: app:1\n")
+	writeFile(t, filepath.Join(s.catalogPath, "app", "app.json"), `{"name":"App","hooks":{"pre-destroy":[{"command":["backup"]}]}}`)
+
+	// Adopted projects don't run catalog hooks
+	var output strings.Builder
+	if err := s.runDeploymentHooks(&output, &LocalDeployment{Software: "app", Directory: dir, Adopted: true}, hookPreDestroy); err != nil {
+		t.Fatalf("runDeploymentHooks: %v", err)
+	}
+	if calls := dockerCalls(t, log); len(calls) != 0 {
+		t.Errorf("adopted project ran hooks: %q", calls)
+	}
+
+	if err := s.runDeploymentHooks(&output, &LocalDeployment{Software: "app", Directory: dir}, hookPreDestroy); err != nil {
+		t.Fatalf("runDeploymentHooks: %v", err)
+	}
+	if calls := dockerCalls(t, log); len(calls) != 2 || calls[1] != "compose run --rm web backup" {
+		t.Errorf("calls = %q, want the backup step", calls)
+	}
+
+	// A deployment whose catalog entry is gone skips its hooks with a warning
+	output.Reset()
+	if err := s.runDeploymentHooks(&output, &LocalDeployment{Software: "removed", Directory: dir}, hookPreDestroy); err != nil {
+		t.Fatalf("runDeploymentHooks: %v", err)
+	}
+	if !strings.Contains(output.String(), "Skipped the pre-destroy hooks: software 'removed' not found in catalog") {
+		t.Errorf("output = %q, want a skip warning", output.String())
+	}
+}
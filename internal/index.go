@@ -0,0 +1,211 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	catalogIndexFileName = "catalog-index.json"
+
+	// catalogIndexVersion changes whenever the index format does, forcing a rebuild
+	catalogIndexVersion = 1
+)
+
+// catalogIndex is every catalog entry's app.json, collected into one file so
+// listing and searching don't read the whole catalog
+type catalogIndex struct {
+	Version int                     `json:"version"`
+	Entries []catalogEntry          `json:"entries"`
+	Stamps  map[string]catalogStamp `json:"stamps"`
+}
+
+// catalogEntry is one indexed catalog entry
+type catalogEntry struct {
+	Slug   string        `json:"slug"`
+	Detail CatalogDetail `json:"detail"`
+}
+
+// catalogStamp identifies the app.json an entry was indexed from. A size of
+// -1 means the entry has no app.json.
+type catalogStamp struct {
+	ModTime int64  `json:"mod_time"`
+	Size    int64  `json:"size"`
+	Hash    string `json:"hash,omitempty"`
+}
+
+func (s *Service) catalogIndexPath() string {
+	return filepath.Join(s.configPath, catalogIndexFileName)
+}
+
+// catalogStamps stats every entry's app.json, which is much cheaper than reading them
+func (s *Service) catalogStamps() (map[string]catalogStamp, error) {
+	items, err := s.listCatalogItems()
+	if err != nil {
+		return nil, err
+	}
+
+	stamps := make(map[string]catalogStamp, len(items))
+	for _, slug := range items {
+		info, err := os.Stat(filepath.Join(s.catalogPath, slug, "app.json"))
+		if err != nil {
+			stamps[slug] = catalogStamp{Size: -1}
+			continue
+		}
+		stamps[slug] = catalogStamp{ModTime: info.ModTime().UnixNano(), Size: info.Size()}
+	}
+	return stamps, nil
+}
+
+// isCurrent reports whether the index was built from the app.json files described by stamps
+func (idx *catalogIndex) isCurrent(stamps map[string]catalogStamp) bool {
+	if idx.Version != catalogIndexVersion || len(idx.Stamps) != len(stamps) {
+		return false
+	}
+	for slug, stamp := range stamps {
+		indexed, ok := idx.Stamps[slug]
+		if !ok || indexed.ModTime != stamp.ModTime || indexed.Size != stamp.Size {
+			return false
+		}
+	}
+	return true
+}
+
+// buildCatalogIndex indexes every app.json in the catalog. Entries whose
+// content hash matches the previous index are reused without parsing, and
+// entries with an invalid app.json are left out.
+func (s *Service) buildCatalogIndex(previous *catalogIndex) (*catalogIndex, error) {
+	stamps, err := s.catalogStamps()
+	if err != nil {
+		return nil, err
+	}
+
+	reusable := make(map[string]catalogEntry)
+	if previous != nil && previous.Version == catalogIndexVersion {
+		for _, entry := range previous.Entries {
+			reusable[entry.Slug] = entry
+		}
+	}
+
+	index := &catalogIndex{Version: catalogIndexVersion, Stamps: make(map[string]catalogStamp, len(stamps))}
+	for _, slug := range sortedKeys(stamps) {
+		stamp := stamps[slug]
+		if stamp.Size < 0 {
+			index.Stamps[slug] = stamp
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.catalogPath, slug, "app.json"))
+		if err != nil {
+			index.Stamps[slug] = catalogStamp{Size: -1}
+			continue
+		}
+		sum := sha256.Sum256(data)
+		stamp.Hash = hex.EncodeToString(sum[:])
+		index.Stamps[slug] = stamp
+
+		if entry, ok := reusable[slug]; ok && previous.Stamps[slug].Hash == stamp.Hash {
+			index.Entries = append(index.Entries, entry)
+			continue
+		}
+
+		var detail CatalogDetail
+		if err := json.Unmarshal(data, &detail); err != nil {
+			continue
+		}
+		index.Entries = append(index.Entries, catalogEntry{Slug: slug, Detail: detail})
+	}
+
+	return index, nil
+}
+
+// readCatalogIndex reads the saved index, returning nil if there is none or it can't be parsed
+func (s *Service) readCatalogIndex() *catalogIndex {
+	data, err := os.ReadFile(s.catalogIndexPath())
+	if err != nil {
+		return nil
+	}
+
+	var index catalogIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil
+	}
+	return &index
+}
+
+// writeCatalogIndex rebuilds the index and saves it next to the catalog
+func (s *Service) writeCatalogIndex() (*catalogIndex, error) {
+	index, err := s.buildCatalogIndex(s.readCatalogIndex())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.saveCatalogIndex(index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+func (s *Service) saveCatalogIndex(index *catalogIndex) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := s.catalogIndexPath() + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write catalog index: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.catalogIndexPath()); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write catalog index: %w", err)
+	}
+	return nil
+}
+
+// loadCatalogIndex returns the catalog index, rebuilding it first when the
+// catalog changed since it was built, e.g. after editing an entry by hand
+func (s *Service) loadCatalogIndex() (*catalogIndex, error) {
+	if _, err := os.Stat(s.catalogPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("catalog not found. Run 'opensourcer update' first")
+	}
+
+	stamps, err := s.catalogStamps()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog: %w", err)
+	}
+
+	index := s.readCatalogIndex()
+	if index != nil && index.isCurrent(stamps) {
+		return index, nil
+	}
+
+	index, err = s.buildCatalogIndex(index)
+	if err != nil {
+		return nil, err
+	}
+
+	// A read-only config directory shouldn't stop catalog commands working
+	_ = s.saveCatalogIndex(index)
+
+	return index, nil
+}
+
+// lookupCatalogEntry returns the indexed details of a catalog entry
+func (s *Service) lookupCatalogEntry(software string) (*CatalogDetail, error) {
+	index, err := s.loadCatalogIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range index.Entries {
+		if entry.Slug == software {
+			return &entry.Detail, nil
+		}
+	}
+	return nil, fmt.Errorf("software '%s' not found in catalog", software)
+}
@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCatalogIndexIsCurrent(t *testing.T) {
+	stamps := map[string]catalogStamp{
+		"gitea":    {ModTime: 100, Size: 200},
+		"jellyfin": {Size: -1},
+	}
+
+	tests := []struct {
+		name   string
+		index  catalogIndex
+		stamps map[string]catalogStamp
+		want   bool
+	}{
+		{
+			name:  "unchanged",
+			index: catalogIndex{Version: catalogIndexVersion, Stamps: map[string]catalogStamp{"gitea": {ModTime: 100, Size: 200, Hash: "abc"}, "jellyfin": {Size: -1}}},
+			want:  true,
+		},
+		{
+			name:  "older format",
+			index: catalogIndex{Version: catalogIndexVersion - 1, Stamps: stamps},
+		},
+		{
+			name:  "entry added",
+			index: catalogIndex{Version: catalogIndexVersion, Stamps: map[string]catalogStamp{"gitea": {ModTime: 100, Size: 200}}},
+		},
+		{
+			name:   "entry removed",
+			index:  catalogIndex{Version: catalogIndexVersion, Stamps: stamps},
+			stamps: map[string]catalogStamp{"gitea": {ModTime: 100, Size: 200}},
+		},
+		{
+			name:   "entry renamed",
+			index:  catalogIndex{Version: catalogIndexVersion, Stamps: stamps},
+			stamps: map[string]catalogStamp{"gitea": {ModTime: 100, Size: 200}, "jellyfin2": {Size: -1}},
+		},
+		{
+			name:   "app.json touched",
+			index:  catalogIndex{Version: catalogIndexVersion, Stamps: stamps},
+			stamps: map[string]catalogStamp{"gitea": {ModTime: 101, Size: 200}, "jellyfin": {Size: -1}},
+		},
+		{
+			name:   "app.json resized",
+			index:  catalogIndex{Version: catalogIndexVersion, Stamps: stamps},
+			stamps: map[string]catalogStamp{"gitea": {ModTime: 100, Size: 201}, "jellyfin": {Size: -1}},
+		},
+		{
+			name:   "app.json added",
+			index:  catalogIndex{Version: catalogIndexVersion, Stamps: stamps},
+			stamps: map[string]catalogStamp{"gitea": {ModTime: 100, Size: 200}, "jellyfin": {ModTime: 5, Size: 10}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := tt.stamps
+			if current == nil {
+				current = stamps
+			}
+			if got := tt.index.isCurrent(current); got != tt.want {
+				t.Errorf("isCurrent = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCatalogIndex(t *testing.T) {
+	s := newTestService(t)
+	writeFile(t, filepath.Join(s.catalogPath, "gitea", "app.json"), `{"name": "Gitea"}`)
+	writeFile(t, filepath.Join(s.catalogPath, "wiki", "app.json"), `{"name": "Wiki"}`)
+	writeFile(t, filepath.Join(s.catalogPath, "broken", "app.json"), `{"name":`)
+	writeFile(t, filepath.Join(s.catalogPath, "empty", "docker-compose.yaml"), "services: {}\n")
+	writeFile(t, filepath.Join(s.catalogPath, "_template", "app.json"), `{"name": "Template"}`)
+
+	first, err := s.buildCatalogIndex(nil)
+	if err != nil {
+		t.Fatalf("buildCatalogIndex: %v", err)
+	}
+	if got := indexedNames(first); got != "gitea=Gitea wiki=Wiki" {
+		t.Fatalf("entries = %s, want gitea and wiki", got)
+	}
+	if first.Stamps["empty"].Size != -1 || first.Stamps["gitea"].Hash == "" || first.Stamps["broken"].Hash == "" {
+		t.Errorf("stamps = %+v", first.Stamps)
+	}
+	if _, ok := first.Stamps["_template"]; ok {
+		t.Error("template directory indexed")
+	}
+
+	// Mark the previous entries so reuse is visible, then change wiki only
+	for i := range first.Entries {
+		first.Entries[i].Detail.Name += " (cached)"
+	}
+	writeFile(t, filepath.Join(s.catalogPath, "wiki", "app.json"), `{"name": "Wiki.js"}`)
+
+	tests := []struct {
+		name     string
+		previous *catalogIndex
+		want     string
+	}{
+		{name: "unchanged entries reused", previous: first, want: "gitea=Gitea (cached) wiki=Wiki.js"},
+		{name: "older format rebuilt", previous: &catalogIndex{Version: catalogIndexVersion - 1, Entries: first.Entries, Stamps: first.Stamps}, want: "gitea=Gitea wiki=Wiki.js"},
+		{name: "no previous index", want: "gitea=Gitea wiki=Wiki.js"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, err := s.buildCatalogIndex(tt.previous)
+			if err != nil {
+				t.Fatalf("buildCatalogIndex: %v", err)
+			}
+			if got := indexedNames(index); got != tt.want {
+				t.Errorf("entries = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadCatalogIndex(t *testing.T) {
+	s := newTestService(t)
+	if _, err := s.loadCatalogIndex(); err == nil || !strings.Contains(err.Error(), "opensourcer update") {
+		t.Fatalf("error = %v, want a hint to update the catalog", err)
+	}
+
+	appJSON := filepath.Join(s.catalogPath, "gitea", "app.json")
+	writeFile(t, appJSON, `{"name": "Gitea"}`)
+	if _, err := s.loadCatalogIndex(); err != nil {
+		t.Fatalf("loadCatalogIndex: %v", err)
+	}
+	if !pathExists(s.catalogIndexPath()) {
+		t.Fatal("index was not saved")
+	}
+
+	// An entry edited by hand is picked up without running update
+	writeFile(t, appJSON, `{"name": "Gitea Edited"}`)
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(appJSON, later, later); err != nil {
+		t.Fatal(err)
+	}
+	detail, err := s.lookupCatalogEntry("gitea")
+	if err != nil {
+		t.Fatalf("lookupCatalogEntry: %v", err)
+	}
+	if detail.Name != "Gitea Edited" {
+		t.Errorf("name = %s, want the edited name", detail.Name)
+	}
+	if _, err := s.lookupCatalogEntry("missing"); err == nil {
+		t.Error("expected an error for an unknown entry")
+	}
+}
+
+// indexedNames lists the index entries as slug=name in order
+func indexedNames(index *catalogIndex) string {
+	var names []string
+	for _, entry := range index.Entries {
+		names = append(names, entry.Slug+"="+entry.Detail.Name)
+	}
+	return strings.Join(names, " ")
+}
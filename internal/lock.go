@@ -0,0 +1,210 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// lockFile pins every service's image to the digest resolved at deploy time.
+// It is a compose override, so every compose command runs the pinned images.
+const lockFile = "docker-compose.lock.yaml"
+
+// composeImages returns the image each service runs, as configured before locking
+func composeImages(dir string) (map[string]string, error) {
+	var stderr bytes.Buffer
+	cmd := unlockedComposeCommand(dir, "config", "--format", "json")
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compose config: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	var config struct {
+		Services map[string]struct {
+			Image string `json:"image"`
+		} `json:"services"`
+	}
+	if err := json.Unmarshal(out, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse compose config: %w", err)
+	}
+
+	images := make(map[string]string)
+	for name, svc := range config.Services {
+		if svc.Image != "" {
+			images[name] = svc.Image
+		}
+	}
+	return images, nil
+}
+
+// lockImages resolves the images of the compose project in dir to digests and
+// writes the lock file. Services whose image is unchanged since previous keep
+// their pinned digest unless refresh is set. Images without a registry
+// digest, such as local builds, are left unpinned.
+func lockImages(dir string, previous map[string]LockedImage, refresh bool) (map[string]LockedImage, error) {
+	images, err := composeImages(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	locked := make(map[string]LockedImage)
+	var resolve []string
+	for _, name := range sortedKeys(images) {
+		if prev, ok := previous[name]; ok && !refresh && prev.Image == images[name] {
+			locked[name] = prev
+			continue
+		}
+		resolve = append(resolve, name)
+	}
+
+	if len(resolve) > 0 {
+		var stderr bytes.Buffer
+		pull := unlockedComposeCommand(dir, append([]string{"pull"}, resolve...)...)
+		pull.Stderr = &stderr
+		if err := pull.Run(); err != nil {
+			return nil, fmt.Errorf("docker compose pull failed: %s", strings.TrimSpace(stderr.String()))
+		}
+
+		for _, name := range resolve {
+			digest, err := imageDigest(images[name])
+			if err != nil {
+				return nil, err
+			}
+			if digest != "" {
+				locked[name] = LockedImage{Image: images[name], Digest: digest}
+			}
+		}
+	}
+
+	if err := writeLockFile(dir, locked); err != nil {
+		return nil, err
+	}
+	return locked, nil
+}
+
+// imageDigest returns the repo@sha256 reference of a pulled image, or "" if
+// it has none
+func imageDigest(image string) (string, error) {
+	if strings.Contains(image, "@") {
+		return image, nil
+	}
+
+	out, err := exec.Command("docker", "image", "inspect", "--format", "{{json .RepoDigests}}", image).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect image %s: %w", image, err)
+	}
+
+	var digests []string
+	if err := json.Unmarshal(out, &digests); err != nil {
+		return "", fmt.Errorf("unexpected output inspecting %s: %w", image, err)
+	}
+
+	repo := normalizeRepo(imageRepo(image))
+	for _, d := range digests {
+		name, _, _ := strings.Cut(d, "@")
+		if normalizeRepo(name) == repo {
+			return d, nil
+		}
+	}
+	return "", nil
+}
+
+// imageRepo strips the tag and digest from an image reference
+func imageRepo(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// normalizeRepo drops the parts Docker Hub references may or may not spell out
+func normalizeRepo(repo string) string {
+	repo = strings.TrimPrefix(repo, "docker.io/")
+	repo = strings.TrimPrefix(repo, "index.docker.io/")
+	return strings.TrimPrefix(repo, "library/")
+}
+
+// imageID returns the local ID of an image
+func imageID(image string) (string, error) {
+	out, err := exec.Command("docker", "image", "inspect", "--format", "{{.Id}}", image).Output()
+	if err != nil {
+		return "", fmt.Errorf("image %s is not available locally", image)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// lockFileContent renders the pinned images as a compose override, recording
+// the image each digest was resolved from. Images pinned by ID keep their tag
+// and are never pulled, so they can't be swapped for a different image.
+func lockFileContent(locked map[string]LockedImage) string {
+	var b strings.Builder
+	b.WriteString("# Generated by opensourcer: images pinned to the digests resolved at deploy.\n")
+	b.WriteString("# Re-resolve with 'opensourcer start --refresh-lock' or 'opensourcer upgrade --refresh-lock'.\n")
+	b.WriteString("x-opensourcer-lock:\n")
+	for _, name := range sortedKeys(locked) {
+		b.WriteString(fmt.Sprintf("  %s: %q\n", name, locked[name].Image))
+	}
+	b.WriteString("services:\n")
+	for _, name := range sortedKeys(locked) {
+		img := locked[name]
+		if img.ID != "" {
+			b.WriteString(fmt.Sprintf("  %s:\n    image: %s\n    pull_policy: never\n", name, img.Image))
+			continue
+		}
+		b.WriteString(fmt.Sprintf("  %s:\n    image: %s\n", name, img.Digest))
+	}
+	return b.String()
+}
+
+// writeLockFile writes the lock file for the pinned images. With no pinned
+// images it removes the file.
+func writeLockFile(dir string, locked map[string]LockedImage) error {
+	path := filepath.Join(dir, lockFile)
+	if len(locked) == 0 {
+		_ = os.Remove(path)
+		return nil
+	}
+
+	if err := os.WriteFile(path, []byte(lockFileContent(locked)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", lockFile, err)
+	}
+	return nil
+}
+
+// restoreLockFile rewrites the lock file from the deployment record if it is
+// missing or was changed by hand
+func restoreLockFile(deployment *LocalDeployment) error {
+	if len(deployment.Images) == 0 {
+		return nil
+	}
+
+	current, err := os.ReadFile(filepath.Join(deployment.Directory, lockFile))
+	if err == nil && string(current) == lockFileContent(deployment.Images) {
+		return nil
+	}
+
+	return writeLockFile(deployment.Directory, deployment.Images)
+}
+
+// writeLockChanges lists the services whose pinned digest changed
+func writeLockChanges(output *strings.Builder, previous, current map[string]LockedImage) {
+	output.WriteString("\n  Image lock:\n")
+	changed := 0
+	for _, name := range sortedKeys(current) {
+		if previous[name].Digest == current[name].Digest {
+			continue
+		}
+		output.WriteString(fmt.Sprintf("    %s: %s\n", name, current[name].Digest))
+		changed++
+	}
+	if changed == 0 {
+		output.WriteString("    all images unchanged\n")
+	}
+}
@@ -0,0 +1,221 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestImageRepo(t *testing.T) {
+	tests := []struct {
+		image string
+		repo  string
+		norm  string
+	}{
+		{image: "postgres", repo: "postgres", norm: "postgres"},
+		{image: "postgres:16", repo: "postgres", norm: "postgres"},
+		{image: "docker.io/library/postgres:16", repo: "docker.io/library/postgres", norm: "postgres"},
+		{image: "index.docker.io/gitea/gitea:1.21", repo: "index.docker.io/gitea/gitea", norm: "gitea/gitea"},
+		{image: "ghcr.io/org/app@sha256:abc", repo: "ghcr.io/org/app", norm: "ghcr.io/org/app"},
+		{image: "registry:5000/app:1", repo: "registry:5000/app", norm: "registry:5000/app"},
+		{image: "registry:5000/app", repo: "registry:5000/app", norm: "registry:5000/app"},
+	}
+
+	for _, tt := range tests {
+		repo := imageRepo(tt.image)
+		if repo != tt.repo {
+			t.Errorf("imageRepo(%s) = %s, want %s", tt.image, repo, tt.repo)
+		}
+		if norm := normalizeRepo(repo); norm != tt.norm {
+			t.Errorf("normalizeRepo(%s) = %s, want %s", repo, norm, tt.norm)
+		}
+	}
+}
+
+func TestImageDigest(t *testing.T) {
+	setupFakeDocker(t, "")
+
+	tests := []struct {
+		name    string
+		image   string
+		digests string
+		want    string
+	}{
+		{
+			name:  "already pinned",
+			image: "postgres@sha256:aaa",
+			want:  "postgres@sha256:aaa",
+		},
+		{
+			name:    "official image",
+			image:   "postgres:16",
+			digests: `["postgres@sha256:aaa"]`,
+			want:    "postgres@sha256:aaa",
+		},
+		{
+			name:    "same image under several repos",
+			image:   "ghcr.io/org/app:1",
+			digests: `["docker.io/org/app@sha256:bbb","ghcr.io/org/app@sha256:ccc"]`,
+			want:    "ghcr.io/org/app@sha256:ccc",
+		},
+		{
+			name:    "spelled out docker hub name",
+			image:   "docker.io/library/redis:7",
+			digests: `["redis@sha256:ddd"]`,
+			want:    "redis@sha256:ddd",
+		},
+		{
+			name:    "local build",
+			image:   "app:dev",
+			digests: `[]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("FAKE_DOCKER_DIGESTS", tt.digests)
+			got, err := imageDigest(tt.image)
+			if err != nil {
+				t.Fatalf("imageDigest: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("imageDigest = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLockFileContent(t *testing.T) {
+	locked := map[string]LockedImage{
+		"web":    {Image: "gitea/gitea:1.21", Digest: "gitea/gitea@sha256:aaa"},
+		"db":     {Image: "postgres:16", Digest: "postgres@sha256:bbb"},
+		"worker": {Image: "app:offline", Digest: "sha256:ccc", ID: "sha256:ccc"},
+	}
+
+	content := lockFileContent(locked)
+
+	var override struct {
+		Lock     map[string]string `yaml:"x-opensourcer-lock"`
+		Services map[string]struct {
+			Image      string `yaml:"image"`
+			PullPolicy string `yaml:"pull_policy"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &override); err != nil {
+		t.Fatalf("lock file is not valid YAML: %v\n%s", err, content)
+	}
+
+	tests := []struct {
+		service    string
+		image      string
+		pullPolicy string
+	}{
+		{service: "web", image: "gitea/gitea@sha256:aaa"},
+		{service: "db", image: "postgres@sha256:bbb"},
+		{service: "worker", image: "app:offline", pullPolicy: "never"},
+	}
+	for _, tt := range tests {
+		svc := override.Services[tt.service]
+		if svc.Image != tt.image || svc.PullPolicy != tt.pullPolicy {
+			t.Errorf("%s = %+v, want image %s with pull policy %q", tt.service, svc, tt.image, tt.pullPolicy)
+		}
+		if override.Lock[tt.service] != locked[tt.service].Image {
+			t.Errorf("%s resolved from %q, want %q", tt.service, override.Lock[tt.service], locked[tt.service].Image)
+		}
+	}
+
+	if lockFileContent(locked) != content {
+		t.Error("lock file content is not stable")
+	}
+	if !strings.HasPrefix(content, "# Generated by opensourcer") {
+		t.Errorf("lock file has no header:\n%s", content)
+	}
+}
+
+func TestRestoreLockFile(t *testing.T) {
+	images := map[string]LockedImage{"web": {Image: "app:1", Digest: "app@sha256:aaa"}}
+	want := lockFileContent(images)
+
+	tests := []struct {
+		name     string
+		existing string
+		images   map[string]LockedImage
+		want     string
+	}{
+		{name: "missing", images: images, want: want},
+		{name: "edited by hand", existing: "services:\n  web:\n    image: app:2\n", images: images, want: want},
+		{name: "unchanged", existing: want, images: images, want: want},
+		{name: "nothing pinned", existing: "# kept\n", want: "# kept\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, lockFile)
+			if tt.existing != "" {
+				writeFile(t, path, tt.existing)
+			}
+
+			if err := restoreLockFile(&LocalDeployment{Directory: dir, Images: tt.images}); err != nil {
+				t.Fatalf("restoreLockFile: %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("lock file = %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteLockFileRemovesEmptyLock(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, lockFile), "services: {}\n")
+
+	if err := writeLockFile(dir, nil); err != nil {
+		t.Fatalf("writeLockFile: %v", err)
+	}
+	if pathExists(filepath.Join(dir, lockFile)) {
+		t.Error("lock file kept with no pinned images")
+	}
+}
+
+func TestWriteLockChanges(t *testing.T) {
+	previous := map[string]LockedImage{
+		"web": {Digest: "app@sha256:aaa"},
+		"db":  {Digest: "postgres@sha256:bbb"},
+	}
+
+	tests := []struct {
+		name    string
+		current map[string]LockedImage
+		want    string
+	}{
+		{name: "unchanged", current: previous, want: "    all images unchanged\n"},
+		{
+			name:    "one digest changed",
+			current: map[string]LockedImage{"web": {Digest: "app@sha256:fff"}, "db": {Digest: "postgres@sha256:bbb"}},
+			want:    "    web: app@sha256:fff\n",
+		},
+		{
+			name:    "service added",
+			current: map[string]LockedImage{"web": {Digest: "app@sha256:aaa"}, "db": {Digest: "postgres@sha256:bbb"}, "cache": {Digest: "redis@sha256:ccc"}},
+			want:    "    cache: redis@sha256:ccc\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output strings.Builder
+			writeLockChanges(&output, previous, tt.current)
+			if got := strings.TrimPrefix(output.String(), "\n  Image lock:\n"); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
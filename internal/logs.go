@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gofr.dev/pkg/gofr"
+)
+
+const defaultLogTail = "100"
+
+// logOptions are the filters accepted by the logs command
+type logOptions struct {
+	Service    string
+	Since      string
+	Until      string
+	Tail       string
+	Follow     bool
+	Timestamps bool
+	Grep       *regexp.Regexp
+}
+
+func parseLogOptions(c *gofr.Context) (*logOptions, error) {
+	opts := &logOptions{
+		Service:    getFlagValue(c, "service"),
+		Since:      getFlagValue(c, "since"),
+		Until:      getFlagValue(c, "until"),
+		Tail:       getFlagValue(c, "tail"),
+		Follow:     c.Param("follow") == "true",
+		Timestamps: c.Param("timestamps") == "true",
+	}
+
+	if opts.Tail == "" {
+		opts.Tail = defaultLogTail
+	}
+	if n, err := strconv.Atoi(opts.Tail); opts.Tail != "all" && (err != nil || n < 0) {
+		return nil, fmt.Errorf("--tail must be a number of lines or 'all'")
+	}
+
+	if pattern := getFlagValue(c, "grep"); pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --grep pattern: %w", err)
+		}
+		opts.Grep = re
+	}
+
+	return opts, nil
+}
+
+// isDefault reports whether no filters were given, i.e. the plain last-lines view
+func (o *logOptions) isDefault() bool {
+	return o.Service == "" && o.Since == "" && o.Until == "" && o.Tail == defaultLogTail &&
+		!o.Follow && !o.Timestamps && o.Grep == nil
+}
+
+// composeArgs builds the docker compose logs arguments for the options
+func (o *logOptions) composeArgs() []string {
+	args := []string{"logs", "--tail", o.Tail}
+	if o.Since != "" {
+		args = append(args, "--since", o.Since)
+	}
+	if o.Until != "" {
+		args = append(args, "--until", o.Until)
+	}
+	if o.Follow {
+		args = append(args, "--follow")
+	}
+	if o.Timestamps {
+		args = append(args, "--timestamps")
+	}
+	if o.Grep != nil {
+		// Colour codes would split the text being matched
+		args = append(args, "--no-color")
+	}
+	if o.Service != "" {
+		args = append(args, o.Service)
+	}
+	return args
+}
+
+// streamDockerLogs writes the deployment's logs straight to stdout as docker
+// compose produces them, so following and large outputs never sit in memory
+func (s *Service) streamDockerLogs(deployment *LocalDeployment, opts *logOptions) (interface{}, error) {
+	fmt.Printf("\n📋 Logs for %s\n%s\n", deployment.Software, strings.Repeat("─", 60))
+
+	cmd := composeCommand(deployment.Directory, opts.composeArgs()...)
+	cmd.Stderr = os.Stderr
+
+	if opts.Grep == nil {
+		cmd.Stdout = os.Stdout
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("failed to get logs: %w", err)
+		}
+		return nil, nil
+	}
+
+	if err := filterCommandOutput(cmd, os.Stdout, opts.Grep); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// filterCommandOutput runs cmd and copies the lines of its output that match
+// re to w. If filtering stops early, nothing reads the pipe any more and the
+// command would block writing to it, so it is killed before waiting.
+func filterCommandOutput(cmd *exec.Cmd, w io.Writer, re *regexp.Regexp) error {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to get logs: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to get logs: %w", err)
+	}
+
+	if err := filterLines(stdout, w, re); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return fmt.Errorf("failed to read logs: %w", err)
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("failed to get logs: %w", err)
+	}
+	return nil
+}
+
+// filterLines copies the lines of r that match re to w, one line at a time
+func filterLines(r io.Reader, w io.Writer, re *regexp.Regexp) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !re.Match(line) {
+			continue
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
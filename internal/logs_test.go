@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"errors"
+	"io"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilterLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		pattern string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "matching lines are kept",
+			input:   "web | GET /\nweb | error: boom\ndb  | ready\ndb  | ERROR: disk\n",
+			pattern: "(?i)error",
+			want:    "web | error: boom\ndb  | ERROR: disk\n",
+		},
+		{
+			name:    "last line without a newline",
+			input:   "a\nerror at end",
+			pattern: "error",
+			want:    "error at end\n",
+		},
+		{
+			name:    "nothing matches",
+			input:   "a\nb\n",
+			pattern: "c",
+		},
+		{
+			name:    "line longer than the buffer",
+			input:   strings.Repeat("x", 2*1024*1024) + "\n",
+			pattern: "x",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			err := filterLines(strings.NewReader(tt.input), &out, regexp.MustCompile(tt.pattern))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("filterLines: %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("output = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func TestFilterLinesWriteError(t *testing.T) {
+	err := filterLines(strings.NewReader("error\n"), failingWriter{}, regexp.MustCompile("error"))
+	if err == nil || !strings.Contains(err.Error(), "broken pipe") {
+		t.Fatalf("error = %v, want the write error", err)
+	}
+}
+
+func TestFilterCommandOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell loop as the log producer")
+	}
+
+	var out strings.Builder
+	cmd := exec.Command("sh", "-c", "echo 'web | ok'; echo 'web | error: boom'")
+	if err := filterCommandOutput(cmd, &out, regexp.MustCompile("error")); err != nil {
+		t.Fatalf("filterCommandOutput: %v", err)
+	}
+	if out.String() != "web | error: boom\n" {
+		t.Errorf("output = %q", out.String())
+	}
+
+	// A producer that keeps writing after the filter gives up, like
+	// logs --follow, must be stopped rather than left blocked on the pipe
+	cmd = exec.Command("sh", "-c", `head -c 2000000 /dev/zero | tr '\0' x; echo; while true; do echo line; done`)
+	done := make(chan error, 1)
+	go func() { done <- filterCommandOutput(cmd, io.Discard, regexp.MustCompile("line")) }()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "failed to read logs") {
+			t.Errorf("error = %v, want a read error", err)
+		}
+	case <-time.After(10 * time.Second):
+		_ = cmd.Process.Kill()
+		t.Fatal("filterCommandOutput blocked after the filter failed")
+	}
+}
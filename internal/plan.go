@@ -0,0 +1,266 @@
+package internal
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "<redacted>"
+
+// secretEnvMarkers are substrings of env variable names whose values are never printed
+var secretEnvMarkers = []string{"PASSWORD", "SECRET", "TOKEN", "KEY"}
+
+// showPlan describes what a deploy would do without touching Docker, the
+// deployment directory or deployments.json
+func (s *Service) showPlan(target string, detail *CatalogDetail, plan *deploymentPlan) (interface{}, error) {
+	envVars := redactEnvVars(detail, plan)
+
+	merged, err := mergedComposeConfig(plan, envVars)
+	if err != nil {
+		return nil, err
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("\n📝 Deployment plan for %s (dry run)\n", detail.Name))
+	output.WriteString(strings.Repeat("-", 60) + "\n\n")
+
+	output.WriteString(fmt.Sprintf("  Target: %s\n", target))
+	if _, err := os.Stat(plan.Dir); err == nil {
+		output.WriteString(fmt.Sprintf("  Directory: %s (exists, files will be overwritten)\n", plan.Dir))
+	} else {
+		output.WriteString(fmt.Sprintf("  Directory: %s (will be created)\n", plan.Dir))
+	}
+	if plan.Port > 0 && target == "local" {
+		output.WriteString(fmt.Sprintf("  URL: http://localhost:%d\n", plan.Port))
+	}
+
+	dropped := make(map[string]bool)
+	for _, ext := range plan.Externals {
+		dropped[ext.Service] = true
+	}
+
+	output.WriteString("\n  Images:\n")
+	for _, name := range plan.Compose.serviceNames() {
+		if dropped[name] {
+			continue
+		}
+		output.WriteString(fmt.Sprintf("    %-40s (%s)\n", plan.Compose.Services[name].Image, name))
+	}
+
+	output.WriteString("\n  Ports:\n")
+	for _, name := range plan.Compose.serviceNames() {
+		if dropped[name] {
+			continue
+		}
+		for _, p := range plan.Compose.Services[name].Ports {
+			if p.Host > 0 {
+				output.WriteString(fmt.Sprintf("    %s -> %s:%s\n", p.hostPorts(), name, p.containerPorts()))
+			}
+		}
+	}
+
+	if len(plan.Externals) > 0 {
+		output.WriteString("\n  External services:\n")
+		for _, ext := range plan.Externals {
+			output.WriteString(fmt.Sprintf("    %s replaced by %s at %s:%d\n", ext.Service, ext.Option, ext.Host, ext.Port))
+		}
+	}
+
+	if len(plan.Limits) > 0 {
+		output.WriteString("\n  Resource limits:\n")
+		for _, name := range sortedKeys(plan.Limits) {
+			output.WriteString(fmt.Sprintf("    %s: %s\n", name, plan.Limits[name]))
+		}
+	}
+
+	if target == "local" {
+		if warnings := resourceWarnings(detail, plan); len(warnings) > 0 {
+			output.WriteString("\n  Warnings:\n")
+			for _, w := range warnings {
+				output.WriteString(fmt.Sprintf("    - %s\n", w))
+			}
+		}
+	}
+
+	if len(plan.Findings) > 0 {
+		output.WriteString("\n  Security audit:\n")
+		writeFindings(&output, "    ", plan.Findings)
+		if s.blockedFindingsError(plan.Findings) != nil {
+			output.WriteString("    The security policy would block this deploy.\n")
+		}
+	}
+
+	if target == "aws" {
+		output.WriteString("\n  AWS resources:\n")
+		output.WriteString("    security group, EC2 instance, EBS data volume\n")
+	}
+
+	if target == "local" && (len(detail.Hooks[hookPreDeploy]) > 0 || len(detail.Hooks[hookPostDeploy]) > 0) {
+		output.WriteString("\n  Hooks:\n")
+		for _, hook := range []string{hookPreDeploy, hookPostDeploy} {
+			for _, step := range detail.Hooks[hook] {
+				output.WriteString(fmt.Sprintf("    %s: %s\n", hook, strings.Join(step.Command, " ")))
+			}
+		}
+	}
+
+	output.WriteString("\n  Environment (.env):\n")
+	for _, key := range sortedKeys(envVars) {
+		output.WriteString(fmt.Sprintf("    %s=%s\n", key, envVars[key]))
+	}
+
+	output.WriteString("\n  Files:\n")
+	files, err := plannedFiles(plan)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		output.WriteString(fmt.Sprintf("    %s\n", f))
+	}
+	if target == "local" {
+		output.WriteString(fmt.Sprintf("    %s (images pinned to digests at deploy)\n", filepath.Join(plan.Dir, lockFile)))
+	}
+
+	output.WriteString("\n  Compose config:\n")
+	for _, line := range strings.Split(strings.TrimRight(merged, "\n"), "\n") {
+		output.WriteString("    " + line + "\n")
+	}
+
+	output.WriteString("\nNo changes were made. Run without --dry-run to deploy.\n")
+
+	return output.String(), nil
+}
+
+// redactEnvVars returns a copy of the plan's env variables with secrets hidden
+func redactEnvVars(detail *CatalogDetail, plan *deploymentPlan) map[string]string {
+	passwordKeys := make(map[string]bool)
+	for key, input := range detail.Inputs {
+		if input.Type == "password" {
+			passwordKeys[inputEnvKey(key)] = true
+		}
+	}
+
+	envVars := make(map[string]string, len(plan.EnvVars))
+	for key, value := range plan.EnvVars {
+		envVars[key] = value
+		if passwordKeys[key] || isSecretEnvKey(key) {
+			envVars[key] = redacted
+		}
+	}
+
+	// DSNs carry credentials in their user info
+	for _, ext := range plan.Externals {
+		for key, value := range ext.envVars() {
+			if !strings.HasSuffix(key, "_URL") {
+				continue
+			}
+			if u, err := url.Parse(value); err == nil {
+				envVars[key] = u.Redacted()
+			}
+		}
+	}
+
+	return envVars
+}
+
+func isSecretEnvKey(key string) bool {
+	for _, marker := range secretEnvMarkers {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergedComposeConfig renders the compose file as it will run: variables
+// interpolated and services replaced by external instances removed
+func mergedComposeConfig(plan *deploymentPlan, envVars map[string]string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(plan.CatalogDir, "docker-compose.yaml"))
+	if err != nil {
+		return "", err
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal([]byte(interpolateCompose(string(data), envVars)), &doc); err != nil {
+		return "", fmt.Errorf("invalid compose file: %w", err)
+	}
+
+	if services, ok := doc["services"].(map[string]interface{}); ok {
+		for _, ext := range plan.Externals {
+			delete(services, ext.Service)
+		}
+
+		for _, svc := range services {
+			def, ok := svc.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			switch deps := def["depends_on"].(type) {
+			case []interface{}:
+				var remaining []interface{}
+				for _, dep := range deps {
+					if _, ok := services[fmt.Sprint(dep)]; ok {
+						remaining = append(remaining, dep)
+					}
+				}
+				def["depends_on"] = remaining
+			case map[string]interface{}:
+				for dep := range deps {
+					if _, ok := services[dep]; !ok {
+						delete(deps, dep)
+					}
+				}
+			}
+			if deps, ok := def["depends_on"]; ok && isEmptyValue(deps) {
+				delete(def, "depends_on")
+			}
+		}
+	}
+
+	return marshalDocuments([]map[string]interface{}{doc})
+}
+
+// plannedFiles lists the files a deploy writes into the deployment directory
+func plannedFiles(plan *deploymentPlan) ([]string, error) {
+	var files []string
+
+	err := filepath.Walk(plan.CatalogDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(plan.CatalogDir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.Join(plan.Dir, rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files = append(files, filepath.Join(plan.Dir, ".env"))
+	if plan.Override != "" {
+		files = append(files, filepath.Join(plan.Dir, externalOverrideFile))
+	}
+	if plan.Resources != "" {
+		files = append(files, filepath.Join(plan.Dir, resourcesOverrideFile))
+	}
+
+	return files, nil
+}
+
+func isEmptyValue(v interface{}) bool {
+	switch v := v.(type) {
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return v == nil
+}
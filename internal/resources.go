@@ -0,0 +1,266 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const resourcesOverrideFile = "docker-compose.resources.yaml"
+
+// memoryPattern matches compose-style memory sizes such as "512m", "1.5g", "2GB" or "2GiB"
+var memoryPattern = regexp.MustCompile(`(?i)^(\d+(?:\.\d+)?)\s*([kmg]?)i?b?$`)
+
+// resourceLimit is the memory and CPU cap applied to one service
+type resourceLimit struct {
+	Memory string
+	CPUs   string
+}
+
+func (l resourceLimit) String() string {
+	var parts []string
+	if l.Memory != "" {
+		parts = append(parts, l.Memory+" memory")
+	}
+	if l.CPUs != "" {
+		parts = append(parts, l.CPUs+" CPUs")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatCPUs formats a CPU count as compose expects it, or "" if it is not set
+func formatCPUs(cpus float64) string {
+	if cpus <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(cpus, 'f', -1, 64)
+}
+
+// resourceOverrides are the --memory and --cpus values given on the command
+// line, keyed by service. The empty key means the default service.
+type resourceOverrides struct {
+	Memory map[string]string
+	CPUs   map[string]string
+}
+
+// parseResourceOverrides reads --memory and --cpus, given either as a bare
+// value for the exposed service or as <service>=<value>
+func parseResourceOverrides() (*resourceOverrides, error) {
+	overrides := &resourceOverrides{Memory: make(map[string]string), CPUs: make(map[string]string)}
+
+	for _, value := range getFlagValues("memory") {
+		service, limit := splitServiceValue(value)
+		if _, err := parseMemory(limit); err != nil {
+			return nil, fmt.Errorf("invalid --memory %q: %w", value, err)
+		}
+		overrides.Memory[service] = limit
+	}
+
+	for _, value := range getFlagValues("cpus") {
+		service, limit := splitServiceValue(value)
+		if cpus, err := strconv.ParseFloat(limit, 64); err != nil || cpus <= 0 {
+			return nil, fmt.Errorf("invalid --cpus %q, expected a number of CPUs like 1.5", value)
+		}
+		overrides.CPUs[service] = limit
+	}
+
+	return overrides, nil
+}
+
+func splitServiceValue(value string) (string, string) {
+	if service, limit, ok := strings.Cut(value, "="); ok {
+		return service, limit
+	}
+	return "", value
+}
+
+// resolveResourceLimits combines the catalog's recommended resources with the
+// command line overrides, for the services that will run
+func resolveResourceLimits(detail *CatalogDetail, compose *ComposeFile, externals []externalService, overrides *resourceOverrides) (map[string]resourceLimit, error) {
+	dropped := make(map[string]bool)
+	for _, ext := range externals {
+		dropped[ext.Service] = true
+	}
+
+	limits := make(map[string]resourceLimit)
+	for name, info := range detail.Services {
+		if _, ok := compose.Services[name]; !ok || dropped[name] {
+			continue
+		}
+		limit := resourceLimit{Memory: info.Resources.Memory, CPUs: formatCPUs(info.Resources.CPUs)}
+		if limit != (resourceLimit{}) {
+			limits[name] = limit
+		}
+	}
+
+	if overrides == nil {
+		return limits, nil
+	}
+
+	resolve := func(service string) (string, error) {
+		if service == "" {
+			service = defaultService(detail, compose)
+		}
+		if _, ok := compose.Services[service]; !ok || dropped[service] {
+			return "", fmt.Errorf("service '%s' not found. Available services: %s", service, strings.Join(compose.serviceNames(), ", "))
+		}
+		return service, nil
+	}
+
+	for service, memory := range overrides.Memory {
+		name, err := resolve(service)
+		if err != nil {
+			return nil, err
+		}
+		limit := limits[name]
+		limit.Memory = memory
+		limits[name] = limit
+	}
+	for service, cpus := range overrides.CPUs {
+		name, err := resolve(service)
+		if err != nil {
+			return nil, err
+		}
+		limit := limits[name]
+		limit.CPUs = cpus
+		limits[name] = limit
+	}
+
+	return limits, nil
+}
+
+// resourcesOverride renders a compose override applying the limits. It
+// returns an empty string when there are none.
+func resourcesOverride(limits map[string]resourceLimit) string {
+	if len(limits) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("# Generated by opensourcer: memory and CPU limits\n")
+	b.WriteString("services:\n")
+
+	for _, name := range sortedKeys(limits) {
+		limit := limits[name]
+		b.WriteString(fmt.Sprintf("  %s:\n    deploy:\n      resources:\n        limits:\n", name))
+		if limit.Memory != "" {
+			b.WriteString(fmt.Sprintf("          memory: %s\n", limit.Memory))
+		}
+		if limit.CPUs != "" {
+			b.WriteString(fmt.Sprintf("          cpus: %q\n", limit.CPUs))
+		}
+	}
+
+	return b.String()
+}
+
+// resourceWarnings compares the planned limits and the Docker host against
+// the minimum resources the catalog declares
+func resourceWarnings(detail *CatalogDetail, plan *deploymentPlan) []string {
+	var warnings []string
+	var minMemory int64
+	var minCPUs float64
+
+	for _, name := range sortedKeys(plan.Limits) {
+		limit := plan.Limits[name]
+		min := detail.Services[name].Resources
+
+		if min.MinMemory != "" {
+			required, _ := parseMemory(min.MinMemory)
+			if limited, err := parseMemory(limit.Memory); err == nil && limit.Memory != "" && limited < required {
+				warnings = append(warnings, fmt.Sprintf("%s is limited to %s of memory but needs at least %s", name, limit.Memory, min.MinMemory))
+			}
+		}
+		if min.MinCPUs > 0 {
+			if limited, err := strconv.ParseFloat(limit.CPUs, 64); err == nil && limited < min.MinCPUs {
+				warnings = append(warnings, fmt.Sprintf("%s is limited to %s CPUs but needs at least %g", name, limit.CPUs, min.MinCPUs))
+			}
+		}
+	}
+
+	dropped := make(map[string]bool)
+	for _, ext := range plan.Externals {
+		dropped[ext.Service] = true
+	}
+	for name, info := range detail.Services {
+		if _, ok := plan.Compose.Services[name]; !ok || dropped[name] {
+			continue
+		}
+		if bytes, err := parseMemory(info.Resources.MinMemory); err == nil && info.Resources.MinMemory != "" {
+			minMemory += bytes
+		}
+		minCPUs += info.Resources.MinCPUs
+	}
+
+	if minMemory == 0 && minCPUs == 0 {
+		return warnings
+	}
+
+	hostMemory, hostCPUs, err := dockerHostResources()
+	if err != nil {
+		return warnings
+	}
+	if minMemory > hostMemory {
+		warnings = append(warnings, fmt.Sprintf("%s needs at least %s of memory but Docker has %s", detail.Name, formatMemory(minMemory), formatMemory(hostMemory)))
+	}
+	if minCPUs > float64(hostCPUs) {
+		warnings = append(warnings, fmt.Sprintf("%s needs at least %g CPUs but Docker has %d", detail.Name, minCPUs, hostCPUs))
+	}
+
+	return warnings
+}
+
+// dockerHostResources returns the memory and CPUs available to Docker, which
+// on macOS and Windows is the Docker VM rather than the machine
+func dockerHostResources() (int64, int, error) {
+	out, err := exec.Command("docker", "info", "--format", "{{.MemTotal}} {{.NCPU}}").Output()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var memory int64
+	var cpus int
+	if _, err := fmt.Sscan(string(out), &memory, &cpus); err != nil {
+		return 0, 0, fmt.Errorf("unexpected docker info output: %w", err)
+	}
+	return memory, cpus, nil
+}
+
+// formatMemory formats bytes with the binary units parseMemory reads, e.g.
+// "512MiB" for 512m, so sizes print as the catalog and --memory wrote them
+func formatMemory(bytes int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB"}
+	size := float64(bytes)
+	i := 0
+	for size >= 1024 && i < len(units)-1 {
+		size /= 1024
+		i++
+	}
+	return strconv.FormatFloat(math.Round(size*100)/100, 'f', -1, 64) + units[i]
+}
+
+// parseMemory parses a compose-style memory size into bytes
+func parseMemory(value string) (int64, error) {
+	m := memoryPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return 0, fmt.Errorf("expected a size like 512m or 2g")
+	}
+
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	switch strings.ToLower(m[2]) {
+	case "k":
+		n *= 1 << 10
+	case "m":
+		n *= 1 << 20
+	case "g":
+		n *= 1 << 30
+	}
+	return int64(n), nil
+}
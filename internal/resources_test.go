@@ -0,0 +1,90 @@
+package internal
+
+import "testing"
+
+func TestParseMemory(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "1024", want: 1024},
+		{value: "512k", want: 512 << 10},
+		{value: "512m", want: 512 << 20},
+		{value: "512MB", want: 512 << 20},
+		{value: "512MiB", want: 512 << 20},
+		{value: "2g", want: 2 << 30},
+		{value: "1.5G", want: 3 << 29},
+		{value: " 256m ", want: 256 << 20},
+		{value: "", wantErr: true},
+		{value: "-1g", wantErr: true},
+		{value: "2t", wantErr: true},
+		{value: "lots", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseMemory(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %d", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMemory: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatMemory(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{bytes: 512, want: "512B"},
+		{bytes: 512 << 10, want: "512KiB"},
+		{bytes: 512 << 20, want: "512MiB"},
+		{bytes: 3 << 29, want: "1.5GiB"},
+		{bytes: 2 << 30, want: "2GiB"},
+		{bytes: 8 << 40, want: "8192GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatMemory(tt.bytes); got != tt.want {
+			t.Errorf("formatMemory(%d) = %s, want %s", tt.bytes, got, tt.want)
+		}
+	}
+}
+
+func TestFormatMemoryRoundTrip(t *testing.T) {
+	for _, value := range []string{"256k", "512m", "768m", "1g", "1.5g", "1536m", "2g", "4.25g"} {
+		bytes, err := parseMemory(value)
+		if err != nil {
+			t.Fatalf("parseMemory(%s): %v", value, err)
+		}
+		formatted := formatMemory(bytes)
+		again, err := parseMemory(formatted)
+		if err != nil {
+			t.Fatalf("parseMemory(%s): %v", formatted, err)
+		}
+		if again != bytes {
+			t.Errorf("%s formats as %s, which parses to %d instead of %d", value, formatted, again, bytes)
+		}
+	}
+}
+
+func TestMinimumResources(t *testing.T) {
+	detail := &CatalogDetail{Services: map[string]ServiceInfo{
+		"web": {Resources: ServiceResources{MinMemory: "512m", MinCPUs: 0.5}},
+		"db":  {Resources: ServiceResources{MinMemory: "1g", MinCPUs: 1}},
+	}}
+
+	if got, want := minimumResources(detail), "at least 1.5GiB memory, 1.5 CPUs"; got != want {
+		t.Errorf("minimumResources = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gofr.dev/pkg/gofr"
+)
+
+// RunAction lists the management actions the catalog defines for a
+// deployment, or runs one of them
+func (s *Service) RunAction(c *gofr.Context) (interface{}, error) {
+	software := getArg(c)
+	if software == "" {
+		return nil, fmt.Errorf("usage: opensourcer run <software> [<action> [--param <name>=<value>]...]")
+	}
+
+	deployment, compose, err := s.findLocalProject(software)
+	if err != nil {
+		return nil, err
+	}
+
+	detail, err := s.getCatalogDetail(deployment.Software)
+	if err != nil {
+		return nil, err
+	}
+
+	name := getSecondArg()
+	if name == "" {
+		return listActions(software, detail), nil
+	}
+
+	action, ok := detail.Actions[name]
+	if !ok {
+		return nil, fmt.Errorf("action '%s' not found. Run 'opensourcer run %s' to list the available actions", name, software)
+	}
+
+	params, err := parseActionParams(action)
+	if err != nil {
+		return nil, err
+	}
+
+	service := action.Service
+	if service == "" {
+		service = defaultService(detail, compose)
+	}
+	if _, ok := compose.Services[service]; !ok {
+		return nil, fmt.Errorf("action '%s' runs in service '%s', which this deployment doesn't have", name, service)
+	}
+
+	command := actionCommand(action, deployment.Directory, params)
+
+	fmt.Printf("\n⚙️  Running %s in %s\n%s\n", name, service, strings.Repeat("-", 60))
+
+	if err := composeExec(deployment, service, command); err != nil {
+		return nil, fmt.Errorf("action '%s' failed: %w", name, err)
+	}
+
+	return fmt.Sprintf("\n✅ %s completed\n", name), nil
+}
+
+// parseActionParams reads --param name=value flags, applying defaults and
+// checking that required params are set
+func parseActionParams(action ActionConfig) (map[string]string, error) {
+	params := make(map[string]string)
+	for _, param := range getFlagValues("param") {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --param %q, expected <name>=<value>", param)
+		}
+		if _, known := action.Params[key]; !known {
+			return nil, fmt.Errorf("unknown param '%s'", key)
+		}
+		params[key] = value
+	}
+
+	var missing []string
+	for _, key := range sortedKeys(action.Params) {
+		if params[key] != "" {
+			continue
+		}
+		config := action.Params[key]
+		if config.Default != "" {
+			params[key] = config.Default
+		} else if config.Required {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required params: %s", strings.Join(missing, ", "))
+	}
+
+	return params, nil
+}
+
+// actionCommand interpolates the action's command arguments with the
+// deployment's .env, params taking precedence
+func actionCommand(action ActionConfig, dir string, params map[string]string) []string {
+	vars, _ := parseEnvFile(filepath.Join(dir, ".env"))
+	if vars == nil {
+		vars = make(map[string]string)
+	}
+	for key, value := range params {
+		vars[key] = value
+	}
+
+	command := make([]string, len(action.Command))
+	for i, arg := range action.Command {
+		command[i] = interpolateCompose(arg, vars)
+	}
+	return command
+}
+
+func listActions(software string, detail *CatalogDetail) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("\n⚙️  Actions for %s\n", detail.Name))
+	output.WriteString(strings.Repeat("-", 60) + "\n\n")
+
+	if len(detail.Actions) == 0 {
+		output.WriteString(fmt.Sprintf("  %s doesn't define any actions.\n", detail.Name))
+		return output.String()
+	}
+
+	for _, name := range sortedKeys(detail.Actions) {
+		action := detail.Actions[name]
+		output.WriteString(fmt.Sprintf("  %-20s %s\n", name, action.Description))
+		for _, key := range sortedKeys(action.Params) {
+			param := action.Params[key]
+			note := ""
+			if param.Required && param.Default == "" {
+				note = " (required)"
+			} else if param.Default != "" {
+				note = fmt.Sprintf(" (default: %s)", param.Default)
+			}
+			output.WriteString(fmt.Sprintf("  %-20s   --param %s=<%s>%s\n", "", key, param.Label, note))
+		}
+	}
+
+	output.WriteString(fmt.Sprintf("\nRun with: opensourcer run %s <action> [--param <name>=<value>]\n", software))
+
+	return output.String()
+}
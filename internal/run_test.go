@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestActionCommand(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".env"), "ADMIN_USER=admin\nDB_NAME=app\nuser=from-env\n")
+
+	tests := []struct {
+		name    string
+		command []string
+		params  map[string]string
+		want    []string
+	}{
+		{
+			name:    "env variables",
+			command: []string{"app", "admin", "reset", "--user", "${ADMIN_USER}"},
+			want:    []string{"app", "admin", "reset", "--user", "admin"},
+		},
+		{
+			name:    "params override env",
+			command: []string{"app", "user", "create", "$user"},
+			params:  map[string]string{"user": "alice"},
+			want:    []string{"app", "user", "create", "alice"},
+		},
+		{
+			name:    "defaults for unset variables",
+			command: []string{"pg_dump", "${DB_NAME}", "-f", "${OUTPUT:-/tmp/dump.sql}"},
+			want:    []string{"pg_dump", "app", "-f", "/tmp/dump.sql"},
+		},
+		{
+			name:    "whole argument kept when a value has spaces",
+			command: []string{"sh", "-c", "echo ${message}"},
+			params:  map[string]string{"message": "hello; rm -rf /"},
+			want:    []string{"sh", "-c", "echo hello; rm -rf /"},
+		},
+		{
+			name:    "unknown variables are empty",
+			command: []string{"app", "${MISSING}"},
+			want:    []string{"app", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := actionCommand(ActionConfig{Command: tt.command}, dir, tt.params)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("actionCommand = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseActionParams(t *testing.T) {
+	action := ActionConfig{Params: map[string]InputConfig{
+		"user":  {Required: true},
+		"role":  {Default: "member"},
+		"notes": {},
+	}}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "defaults applied",
+			args: []string{"--param", "user=alice"},
+			want: map[string]string{"user": "alice", "role": "member"},
+		},
+		{
+			name: "equals sign and values with =",
+			args: []string{"--param=user=alice", "--param", "notes=a=b"},
+			want: map[string]string{"user": "alice", "role": "member", "notes": "a=b"},
+		},
+		{
+			name:    "required missing",
+			args:    []string{"--param", "role=admin"},
+			wantErr: "missing required params: user",
+		},
+		{
+			name:    "unknown param",
+			args:    []string{"--param", "user=alice", "--param", "shell=1"},
+			wantErr: "unknown param 'shell'",
+		},
+		{
+			name:    "no value",
+			args:    []string{"--param", "user"},
+			wantErr: "expected <name>=<value>",
+		},
+	}
+
+	saved := os.Args
+	defer func() { os.Args = saved }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = append([]string{"opensourcer", "run", "app", "create-user"}, tt.args...)
+			got, err := parseActionParams(action)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseActionParams: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("params = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,396 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gofr.dev/pkg/gofr"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+	envNameInvalidChars = regexp.MustCompile(`[^A-Z0-9_]`)
+
+	// managedImageOptions maps database images to the managed option that can replace them
+	managedImageOptions = map[string]string{
+		"postgres": "postgres",
+		"mysql":    "mysql",
+		"mariadb":  "mariadb",
+		"redis":    "redis",
+		"mongo":    "mongodb",
+	}
+)
+
+// scaffoldEntry is the app.json written for a new catalog entry. It mirrors
+// CatalogDetail but leaves out empty fields.
+type scaffoldEntry struct {
+	Name        string                     `json:"name"`
+	Description string                     `json:"description"`
+	Website     string                     `json:"website"`
+	Icon        string                     `json:"icon"`
+	Category    string                     `json:"category"`
+	Tags        []string                   `json:"tags"`
+	Inputs      map[string]scaffoldInput   `json:"inputs"`
+	Services    map[string]scaffoldService `json:"services"`
+}
+
+type scaffoldInput struct {
+	Label       string `json:"label"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required"`
+	Default     string `json:"default,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type scaffoldService struct {
+	Exposed       bool   `json:"exposed"`
+	Stateless     bool   `json:"stateless"`
+	Internal      bool   `json:"internal"`
+	ManagedOption string `json:"managed_option,omitempty"`
+}
+
+// NewCatalogEntry scaffolds a catalog entry from an existing compose file:
+// published services are marked exposed, database images get a managed
+// option, and hard-coded secrets are replaced by password inputs
+func (s *Service) NewCatalogEntry(c *gofr.Context) (interface{}, error) {
+	slug := getSecondArg()
+	from := getFlagValue(c, "from")
+	if slug == "" || from == "" {
+		return nil, fmt.Errorf("usage: opensourcer catalog new <slug> --from <compose file> [--output <dir>]")
+	}
+	if !slugPattern.MatchString(slug) {
+		return nil, fmt.Errorf("invalid slug '%s': use lowercase letters, digits and hyphens", slug)
+	}
+
+	outDir := getFlagValue(c, "output")
+	if outDir == "" {
+		outDir = slug
+	}
+	if pathExists(filepath.Join(outDir, "app.json")) {
+		return nil, fmt.Errorf("%s already contains a catalog entry", outDir)
+	}
+
+	data, err := os.ReadFile(from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", from, err)
+	}
+	compose, err := loadComposeFile(from)
+	if err != nil {
+		return nil, err
+	}
+	if len(compose.Services) == 0 {
+		return nil, fmt.Errorf("%s defines no services", from)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid compose file %s: %w", from, err)
+	}
+	inputs := extractInputs(&doc)
+
+	var rewritten bytes.Buffer
+	enc := yaml.NewEncoder(&rewritten)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	entry := scaffoldEntry{
+		Name:     strings.ToUpper(slug[:1]) + strings.ReplaceAll(slug[1:], "-", " "),
+		Tags:     []string{},
+		Inputs:   inputs,
+		Services: make(map[string]scaffoldService),
+	}
+	for _, name := range compose.serviceNames() {
+		svc := compose.Services[name]
+		published := false
+		for _, p := range svc.Ports {
+			if p.Host > 0 {
+				published = true
+			}
+		}
+		entry.Services[name] = scaffoldService{
+			Exposed:       published,
+			Stateless:     len(svc.Volumes) == 0,
+			Internal:      !published,
+			ManagedOption: managedImageOptions[imageName(svc.Image)],
+		}
+	}
+
+	appJSON, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(outDir, "app.json"), append(appJSON, '\n'), 0644); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(outDir, "docker-compose.yaml"), rewritten.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("\n🧩 Created catalog entry '%s' in %s\n", slug, outDir))
+	output.WriteString(strings.Repeat("-", 60) + "\n\n")
+
+	output.WriteString("  Services:\n")
+	for _, name := range compose.serviceNames() {
+		info := entry.Services[name]
+		var flags []string
+		if info.Exposed {
+			flags = append(flags, "exposed")
+		}
+		if info.Internal {
+			flags = append(flags, "internal")
+		}
+		if info.Stateless {
+			flags = append(flags, "stateless")
+		}
+		if info.ManagedOption != "" {
+			flags = append(flags, "managed: "+info.ManagedOption)
+		}
+		output.WriteString(fmt.Sprintf("    %-20s %s\n", name, strings.Join(flags, ", ")))
+	}
+
+	if len(inputs) > 0 {
+		output.WriteString("\n  Inputs:\n")
+		for _, key := range sortedKeys(inputs) {
+			output.WriteString(fmt.Sprintf("    %-20s %s\n", key, inputs[key].Type))
+		}
+	}
+
+	// Files the compose project mounts from its own directory must ship with the entry
+	fromDir := filepath.Dir(from)
+	var copied, missing, outside []string
+	for _, name := range compose.serviceNames() {
+		for _, v := range compose.Services[name].Volumes {
+			if !strings.HasPrefix(v.Source, ".") {
+				continue
+			}
+			src := filepath.Join(fromDir, v.Source)
+
+			// Sources outside the compose directory would be written outside the entry
+			rel, err := filepath.Rel(fromDir, src)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				outside = append(outside, v.Source)
+				continue
+			}
+			dst := filepath.Join(outDir, rel)
+			info, err := os.Stat(src)
+			if err != nil {
+				missing = append(missing, v.Source)
+				continue
+			}
+			if info.IsDir() {
+				err = os.MkdirAll(dst, 0755)
+				if err == nil {
+					err = copyDir(src, dst)
+				}
+			} else if content, readErr := os.ReadFile(src); readErr != nil {
+				err = readErr
+			} else if err = os.MkdirAll(filepath.Dir(dst), 0755); err == nil {
+				err = os.WriteFile(dst, content, 0644)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to copy %s: %w", v.Source, err)
+			}
+			copied = append(copied, v.Source)
+		}
+	}
+	if len(copied) > 0 {
+		output.WriteString(fmt.Sprintf("\n  Copied mounted files: %s\n", strings.Join(copied, ", ")))
+	}
+	if len(missing) > 0 {
+		output.WriteString(fmt.Sprintf("\n  Mounted files not found, add them to the entry: %s\n", strings.Join(missing, ", ")))
+	}
+	if len(outside) > 0 {
+		output.WriteString(fmt.Sprintf("\n  Mounted files outside the compose directory must be moved into the entry: %s\n", strings.Join(outside, ", ")))
+	}
+
+	if findExposedPort(string(rewritten.Bytes())) == 0 && compose.firstHostPort() > 0 {
+		output.WriteString(fmt.Sprintf("\n  Port %d is not one opensourcer recognises, so deploys won't print a URL\n", compose.firstHostPort()))
+	}
+
+	output.WriteString("\nNext steps:\n")
+	output.WriteString(fmt.Sprintf("  1. Fill in the description, website, category and tags in %s\n", filepath.Join(outDir, "app.json")))
+	output.WriteString(fmt.Sprintf("  2. opensourcer deploy --catalog-dir %s --dry-run\n", outDir))
+	output.WriteString(fmt.Sprintf("  3. opensourcer deploy --catalog-dir %s\n", outDir))
+
+	return output.String(), nil
+}
+
+// extractInputs turns the variables a compose document references into
+// inputs, and replaces hard-coded secrets in service environments with
+// variables so each deploy generates its own. A secret value used by several
+// services, possibly under different names, becomes one variable.
+func extractInputs(doc *yaml.Node) map[string]scaffoldInput {
+	inputs := make(map[string]scaffoldInput)
+
+	addInput := func(name, def string) {
+		key := strings.ToLower(name)
+		if _, ok := inputs[key]; ok {
+			return
+		}
+		input := scaffoldInput{
+			Label: strings.ToUpper(key[:1]) + strings.ReplaceAll(key[1:], "_", " "),
+			Type:  "text",
+		}
+		if isSecretLikeKey(name) {
+			input.Type = "password"
+			input.Description = "Generated if not set"
+		} else {
+			input.Default = def
+		}
+		inputs[key] = input
+	}
+
+	entries := environmentEntries(doc)
+
+	// Name each distinct secret value after the first variable that holds it
+	secretVars := make(map[string]string)
+	for _, e := range entries {
+		value := e.get()
+		if value == "" || composeVarPattern.MatchString(value) || !isSecretLikeKey(e.key) {
+			continue
+		}
+		if _, ok := secretVars[value]; !ok {
+			secretVars[value] = envNameInvalidChars.ReplaceAllString(strings.ToUpper(e.key), "_")
+		}
+	}
+
+	for _, e := range entries {
+		value := e.get()
+		if name, ok := secretVars[value]; ok {
+			addInput(name, "")
+			e.set("${" + name + "}")
+			continue
+		}
+		for _, m := range composeVarPattern.FindAllStringSubmatch(value, -1) {
+			name := m[1]
+			if name == "" {
+				name = m[3]
+			}
+			addInput(name, m[2])
+		}
+	}
+
+	return inputs
+}
+
+// isSecretLikeKey reports whether a variable name suggests it holds a secret,
+// including abbreviations like DB_PASS that aren't redacted elsewhere
+func isSecretLikeKey(key string) bool {
+	key = strings.ToUpper(key)
+	return isSecretEnvKey(key) || strings.Contains(key, "PASS")
+}
+
+// envEntry is one variable in a service's environment, in mapping or list form
+type envEntry struct {
+	key string
+	get func() string
+	set func(string)
+}
+
+// environmentEntries returns the environment variables of every service in a
+// compose document, in service name order
+func environmentEntries(doc *yaml.Node) []envEntry {
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	services := mappingValue(doc.Content[0], "services")
+	if services == nil || services.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	byName := make(map[string]*yaml.Node)
+	var names []string
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		byName[services.Content[i].Value] = services.Content[i+1]
+		names = append(names, services.Content[i].Value)
+	}
+	sort.Strings(names)
+
+	var entries []envEntry
+	for _, name := range names {
+		env := mappingValue(byName[name], "environment")
+		if env == nil {
+			continue
+		}
+		switch env.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(env.Content); i += 2 {
+				value := env.Content[i+1]
+				if value.Kind != yaml.ScalarNode || value.Tag == "!!null" {
+					continue
+				}
+				entries = append(entries, envEntry{
+					key: env.Content[i].Value,
+					get: func() string { return value.Value },
+					set: func(v string) { value.Value, value.Tag, value.Style = v, "!!str", 0 },
+				})
+			}
+		case yaml.SequenceNode:
+			for _, item := range env.Content {
+				key, _, ok := strings.Cut(item.Value, "=")
+				if !ok {
+					continue
+				}
+				entries = append(entries, envEntry{
+					key: key,
+					get: func() string { _, v, _ := strings.Cut(item.Value, "="); return v },
+					set: func(v string) { item.Value = key + "=" + v },
+				})
+			}
+		}
+	}
+	return entries
+}
+
+// mappingValue returns the value node for key in a YAML mapping, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// withCatalogDir returns a service that reads the catalog entry in dir
+// instead of the local catalog, and the entry's slug. The directory name is
+// the slug; software, if set, must match it.
+func (s *Service) withCatalogDir(dir, software string) (*Service, string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	if !pathExists(filepath.Join(dir, "app.json")) {
+		return nil, "", fmt.Errorf("%s is not a catalog entry: app.json not found", dir)
+	}
+
+	slug := filepath.Base(dir)
+	if software != "" && software != slug {
+		return nil, "", fmt.Errorf("catalog directory %s holds '%s', not '%s'", dir, slug, software)
+	}
+
+	local := *s
+	local.catalogPath = filepath.Dir(dir)
+	return &local, slug, nil
+}
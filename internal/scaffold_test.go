@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestExtractInputs(t *testing.T) {
+	source := `
+services:
+  app:
+    image: wiki:${WIKI_VERSION:-2.5}
+    environment:
+      DB_PASS: hunter2
+      SITE_URL: ${SITE_URL:-http://localhost:3000}
+      LOG_LEVEL: info
+  db:
+    image: postgres:16
+    environment:
+      - POSTGRES_PASSWORD=hunter2
+      - POSTGRES_USER=wiki
+  worker:
+    image: wiki:2.5
+    environment:
+      SECRET_KEY: abc123
+      SMTP_PASSWORD: ${SMTP_PASSWORD}
+`
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(source), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := extractInputs(&doc)
+
+	tests := []struct {
+		key      string
+		wantType string
+		wantDef  string
+	}{
+		{key: "site_url", wantType: "text", wantDef: "http://localhost:3000"},
+		{key: "db_pass", wantType: "password"},
+		{key: "secret_key", wantType: "password"},
+		{key: "smtp_password", wantType: "password"},
+	}
+	for _, tt := range tests {
+		input, ok := inputs[tt.key]
+		if !ok {
+			t.Errorf("input %s missing, got %v", tt.key, sortedKeys(inputs))
+			continue
+		}
+		if input.Type != tt.wantType || input.Default != tt.wantDef {
+			t.Errorf("%s = %+v, want type %s and default %q", tt.key, input, tt.wantType, tt.wantDef)
+		}
+	}
+
+	// The shared secret becomes one variable, named after its first use
+	if _, ok := inputs["postgres_password"]; ok {
+		t.Error("shared secret produced a second input")
+	}
+	// Only environment variables become inputs, and literal non-secrets stay as they are
+	for _, key := range []string{"wiki_version", "log_level", "postgres_user"} {
+		if _, ok := inputs[key]; ok {
+			t.Errorf("unexpected input %s", key)
+		}
+	}
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewritten := string(out)
+	if strings.Contains(rewritten, "hunter2") || strings.Contains(rewritten, "abc123") {
+		t.Errorf("hard-coded secrets left in the compose file:\n%s", rewritten)
+	}
+	for _, want := range []string{"DB_PASS: ${DB_PASS}", "POSTGRES_PASSWORD=${DB_PASS}", "SECRET_KEY: ${SECRET_KEY}", "LOG_LEVEL: info"} {
+		if !strings.Contains(rewritten, want) {
+			t.Errorf("rewritten compose file lacks %q:\n%s", want, rewritten)
+		}
+	}
+}
+
+func TestIsSecretLikeKey(t *testing.T) {
+	tests := map[string]bool{
+		"DB_PASS":           true,
+		"POSTGRES_PASSWORD": true,
+		"secret_key":        true,
+		"API_TOKEN":         true,
+		"SITE_URL":          false,
+		"LOG_LEVEL":         false,
+	}
+	for key, want := range tests {
+		if got := isSecretLikeKey(key); got != want {
+			t.Errorf("isSecretLikeKey(%s) = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gofr.dev/pkg/gofr"
+)
+
+// Search finds catalog entries whose name, slug, description or tags match the query
+func (s *Service) Search(c *gofr.Context) (interface{}, error) {
+	query := strings.TrimSpace(strings.Join(positionalArgs(), " "))
+	if query == "" {
+		return nil, fmt.Errorf("usage: opensourcer search <query>")
+	}
+
+	index, err := s.loadCatalogIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := searchEntries(index.Entries, strings.Fields(strings.ToLower(query)))
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("\n🔍 Search results for %q\n", query))
+	output.WriteString(strings.Repeat("-", 60) + "\n\n")
+
+	if len(matches) == 0 {
+		output.WriteString("  No software found.\n\nUse 'opensourcer catalog' to browse everything\n")
+		return output.String(), nil
+	}
+
+	for _, entry := range matches {
+		writeCatalogEntry(&output, entry)
+	}
+
+	output.WriteString(fmt.Sprintf("Found: %d software\n\n", len(matches)))
+	output.WriteString("Use 'opensourcer info <software>' for details\n")
+
+	return output.String(), nil
+}
+
+// searchEntries returns the entries matching every term, best match first
+// and by slug among equal scores
+func searchEntries(entries []catalogEntry, terms []string) []catalogEntry {
+	type match struct {
+		entry catalogEntry
+		score int
+	}
+	var matches []match
+	for _, entry := range entries {
+		if score := searchScore(entry, terms); score > 0 {
+			matches = append(matches, match{entry, score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].entry.Slug < matches[j].entry.Slug
+	})
+
+	results := make([]catalogEntry, len(matches))
+	for i, m := range matches {
+		results[i] = m.entry
+	}
+	return results
+}
+
+// searchScore ranks how well an entry matches every search term. Exact and
+// prefix matches on the name outrank tag and description matches; close
+// misspellings of a word still match. It returns 0 if any term doesn't match.
+func searchScore(entry catalogEntry, terms []string) int {
+	name := strings.ToLower(entry.Detail.Name)
+	slug := strings.ToLower(entry.Slug)
+	description := strings.ToLower(entry.Detail.Description)
+	category := strings.ToLower(entry.Detail.Category)
+
+	var words []string
+	words = append(words, strings.Fields(name)...)
+	words = append(words, slug, category)
+	for _, tag := range entry.Detail.Tags {
+		words = append(words, strings.ToLower(tag))
+	}
+	words = append(words, strings.FieldsFunc(description, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9')
+	})...)
+
+	total := 0
+	for _, term := range terms {
+		score := 0
+		switch {
+		case term == slug || term == name:
+			score = 100
+		case strings.HasPrefix(slug, term) || strings.HasPrefix(name, term):
+			score = 60
+		case strings.Contains(slug, term) || strings.Contains(name, term):
+			score = 40
+		case containsFold(entry.Detail.Tags, term) || term == category:
+			score = 30
+		case strings.Contains(category, term) || strings.Contains(description, term):
+			score = 15
+		default:
+			for _, word := range words {
+				if isFuzzyMatch(term, word) {
+					score = 5
+					break
+				}
+			}
+		}
+		if score == 0 {
+			return 0
+		}
+		total += score
+	}
+
+	return total
+}
+
+// isFuzzyMatch reports whether term is within a small edit distance of word,
+// allowing one typo for short terms and two for longer ones
+func isFuzzyMatch(term, word string) bool {
+	if len(term) < 4 {
+		return false
+	}
+	maxDistance := 1
+	if len(term) >= 8 {
+		maxDistance = 2
+	}
+	// Compare against the start of longer words so "nextclod" finds "nextcloud's"
+	if len(word) > len(term)+maxDistance {
+		word = word[:len(term)+maxDistance]
+	}
+	return editDistance(term, word) <= maxDistance
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeCatalogEntry writes one entry in the catalog listing format
+func writeCatalogEntry(output *strings.Builder, entry catalogEntry) {
+	output.WriteString(fmt.Sprintf("  %-15s %s\n", entry.Slug, entry.Detail.Name))
+	output.WriteString(fmt.Sprintf("                  %s\n", entry.Detail.Description))
+	output.WriteString(fmt.Sprintf("                  Category: %s\n", entry.Detail.Category))
+	if len(entry.Detail.Tags) > 0 {
+		output.WriteString(fmt.Sprintf("                  Tags: %s\n", strings.Join(entry.Detail.Tags, ", ")))
+	}
+	output.WriteString("\n")
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "gitea", b: "gitea", want: 0},
+		{a: "", b: "abc", want: 3},
+		{a: "abc", b: "", want: 3},
+		{a: "gitae", b: "gitea", want: 2},
+		{a: "jelyfin", b: "jellyfin", want: 1},
+		{a: "nextclod", b: "nextcloud", want: 1},
+		{a: "kitten", b: "sitting", want: 3},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestIsFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		term, word string
+		want       bool
+	}{
+		{term: "jelyfin", word: "jellyfin", want: true},
+		{term: "nextclod", word: "nextcloud's", want: true},
+		{term: "nxtclod", word: "nextcloud", want: false},
+		{term: "gitae", word: "gitea", want: false},
+		{term: "gti", word: "git", want: false},
+		{term: "media", word: "medic", want: true},
+		{term: "media", word: "mosaic", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isFuzzyMatch(tt.term, tt.word); got != tt.want {
+			t.Errorf("isFuzzyMatch(%q, %q) = %v, want %v", tt.term, tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestSearchEntries(t *testing.T) {
+	entries := []catalogEntry{
+		{Slug: "nextcloud", Detail: CatalogDetail{Name: "Nextcloud", Description: "Self-hosted file sync and share", Category: "Productivity", Tags: []string{"files", "sync"}}},
+		{Slug: "gitea", Detail: CatalogDetail{Name: "Gitea", Description: "Lightweight Git service", Category: "Development", Tags: []string{"git"}}},
+		{Slug: "forgejo", Detail: CatalogDetail{Name: "Forgejo", Description: "Community fork of Gitea", Category: "Development", Tags: []string{"git"}}},
+		{Slug: "jellyfin", Detail: CatalogDetail{Name: "Jellyfin", Description: "Media server for movies", Category: "Media", Tags: []string{"media", "streaming"}}},
+		{Slug: "seafile", Detail: CatalogDetail{Name: "Seafile", Description: "File sync and share", Category: "Productivity", Tags: []string{"files"}}},
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "gitea", want: []string{"gitea", "forgejo"}},
+		{query: "git", want: []string{"gitea", "forgejo"}},
+		{query: "files", want: []string{"nextcloud", "seafile"}},
+		{query: "file sync", want: []string{"seafile", "nextcloud"}},
+		{query: "Media streaming", want: []string{"jellyfin"}},
+		{query: "nextclod", want: []string{"nextcloud"}},
+		{query: "jelyfin", want: []string{"jellyfin"}},
+		{query: "git media", want: []string{}},
+		{query: "gti", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := []string{}
+			for _, entry := range searchEntries(entries, strings.Fields(strings.ToLower(tt.query))) {
+				got = append(got, entry.Slug)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("search %q = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -7,8 +7,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
-	"time"
 
 	"gofr.dev/pkg/gofr"
 )
@@ -21,7 +21,10 @@ const (
 type Service struct {
 	configPath  string
 	catalogPath string
-	deployments []LocalDeployment
+
+	// pinnedImages, when set, are deployed as-is instead of resolving the
+	// images from a registry, e.g. for a deploy from an offline bundle
+	pinnedImages map[string]LockedImage
 }
 
 // NewService creates a new CLI service
@@ -33,15 +36,10 @@ func NewService() *Service {
 	// Ensure config directory exists
 	_ = os.MkdirAll(configPath, 0755)
 
-	s := &Service{
+	return &Service{
 		configPath:  configPath,
 		catalogPath: catalogPath,
 	}
-
-	// Load existing deployments
-	s.loadDeployments()
-
-	return s
 }
 
 // getArg extracts the first positional argument after the subcommand
@@ -55,40 +53,142 @@ func getArg(c *gofr.Context) string {
 	return ""
 }
 
-// ListCatalog lists available software in the catalog
-func (s *Service) ListCatalog(c *gofr.Context) (interface{}, error) {
-	if _, err := os.Stat(s.catalogPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("catalog not found. Run 'opensourcer update' first")
+// positionalArgs returns the arguments after the subcommand that aren't flags
+func positionalArgs() []string {
+	var args []string
+	if len(os.Args) < 3 {
+		return args
+	}
+	for _, arg := range os.Args[2:] {
+		if !strings.HasPrefix(arg, "-") {
+			args = append(args, arg)
+		}
 	}
+	return args
+}
 
-	entries, err := os.ReadDir(s.catalogPath)
+// getSecondArg returns the positional argument after the first one
+func getSecondArg() string {
+	if len(os.Args) >= 4 {
+		arg := os.Args[3]
+		if !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+	}
+	return ""
+}
+
+// getFlagValues returns every value of a repeatable flag, accepting both
+// --name value and --name=value. Values may themselves contain '='.
+func getFlagValues(name string) []string {
+	var values []string
+	args := os.Args[1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--"+name+"="); ok {
+			values = append(values, value)
+			continue
+		}
+		if arg == "--"+name && i+1 < len(args) {
+			values = append(values, args[i+1])
+			i++
+		}
+	}
+	return values
+}
+
+// getFlagValue returns the value of a flag given as --name value or --name=value
+func getFlagValue(c *gofr.Context, name string) string {
+	if values := getFlagValues(name); len(values) > 0 {
+		return values[len(values)-1]
+	}
+	if val := c.Param(name); val != "true" {
+		return val
+	}
+	return ""
+}
+
+// parseInputs reads the catalog entry's configuration inputs from flags
+func parseInputs(c *gofr.Context, detail *CatalogDetail) map[string]string {
+	inputs := make(map[string]string)
+	for key := range detail.Inputs {
+		if val := c.Param(key); val != "" {
+			inputs[key] = val
+		}
+	}
+	return inputs
+}
+
+// Catalog runs "catalog new" and "catalog test", and lists the catalog
+// otherwise. The subcommand is read from the positional arguments rather than
+// the route, because flag values such as "--tag test" are part of the route.
+func (s *Service) Catalog(c *gofr.Context) (interface{}, error) {
+	switch getArg(c) {
+	case "new":
+		return s.NewCatalogEntry(c)
+	case "test":
+		return s.TestCatalogEntry(c)
+	default:
+		return s.ListCatalog(c)
+	}
+}
+
+// ListCatalog lists available software in the catalog, optionally filtered
+// by category and tag
+func (s *Service) ListCatalog(c *gofr.Context) (interface{}, error) {
+	index, err := s.loadCatalogIndex()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read catalog: %w", err)
+		return nil, err
 	}
 
-	var output strings.Builder
-	output.WriteString("\nAvailable Software\n")
-	output.WriteString(strings.Repeat("-", 60) + "\n\n")
+	category := getFlagValue(c, "category")
+	tag := getFlagValue(c, "tag")
 
-	count := 0
-	for _, entry := range entries {
-		if !entry.IsDir() || strings.HasPrefix(entry.Name(), "_") {
+	var entries []catalogEntry
+	for _, entry := range index.Entries {
+		if category != "" && !strings.EqualFold(entry.Detail.Category, category) {
 			continue
 		}
-
-		slug := entry.Name()
-		detail, err := s.getCatalogDetail(slug)
-		if err != nil {
+		if tag != "" && !containsFold(entry.Detail.Tags, tag) {
 			continue
 		}
+		entries = append(entries, entry)
+	}
+
+	switch sortBy := getFlagValue(c, "sort"); sortBy {
+	case "", "slug":
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Slug < entries[j].Slug })
+	case "name":
+		sort.SliceStable(entries, func(i, j int) bool {
+			return strings.ToLower(entries[i].Detail.Name) < strings.ToLower(entries[j].Detail.Name)
+		})
+	case "category":
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].Detail.Category != entries[j].Detail.Category {
+				return entries[i].Detail.Category < entries[j].Detail.Category
+			}
+			return entries[i].Slug < entries[j].Slug
+		})
+	default:
+		return nil, fmt.Errorf("unknown sort order: %s (use name, slug or category)", sortBy)
+	}
+
+	var output strings.Builder
+	output.WriteString("\nAvailable Software\n")
+	output.WriteString(strings.Repeat("-", 60) + "\n\n")
 
-		output.WriteString(fmt.Sprintf("  %-15s %s\n", slug, detail.Name))
-		output.WriteString(fmt.Sprintf("                  %s\n", detail.Description))
-		output.WriteString(fmt.Sprintf("                  Category: %s\n\n", detail.Category))
-		count++
+	for _, entry := range entries {
+		writeCatalogEntry(&output, entry)
 	}
 
-	output.WriteString(fmt.Sprintf("Total: %d software available\n\n", count))
+	if category != "" || tag != "" {
+		output.WriteString(fmt.Sprintf("Total: %d of %d software match\n\n", len(entries), len(index.Entries)))
+	} else {
+		output.WriteString(fmt.Sprintf("Total: %d software available\n\n", len(entries)))
+	}
 	output.WriteString("Use 'opensourcer info <software>' for details\n")
 	output.WriteString("Use 'opensourcer deploy <software>' to deploy\n")
 
@@ -102,9 +202,15 @@ func (s *Service) GetInfo(c *gofr.Context) (interface{}, error) {
 		return nil, fmt.Errorf("usage: opensourcer info <software>")
 	}
 
-	detail, err := s.getCatalogDetail(software)
+	return s.softwareInfo(software)
+}
+
+// softwareInfo renders a catalog entry with its services, requirements,
+// inputs and deployment state
+func (s *Service) softwareInfo(software string) (string, error) {
+	detail, err := s.lookupCatalogEntry(software)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	var output strings.Builder
@@ -114,28 +220,199 @@ func (s *Service) GetInfo(c *gofr.Context) (interface{}, error) {
 	output.WriteString(fmt.Sprintf("  Website: %s\n", detail.Website))
 	output.WriteString(fmt.Sprintf("  Category: %s\n", detail.Category))
 	output.WriteString(fmt.Sprintf("  Tags: %s\n", strings.Join(detail.Tags, ", ")))
+	if detail.Icon != "" {
+		output.WriteString(fmt.Sprintf("  Icon: %s\n", detail.Icon))
+	}
+
+	// Catalog input defaults resolve versions like ${VERSION:-1.2}
+	defaults := make(map[string]string)
+	for key, input := range detail.Inputs {
+		if input.Default != "" {
+			defaults[inputEnvKey(key)] = input.Default
+		}
+	}
+
+	if compose, err := loadComposeFile(s.getComposePath(software)); err == nil {
+		output.WriteString("\n  Services:\n")
+		for _, name := range compose.serviceNames() {
+			writeServiceInfo(&output, name, compose.Services[name], detail.Services[name], defaults)
+		}
+	}
+
+	if req := minimumResources(detail); req != "" {
+		output.WriteString(fmt.Sprintf("\n  Requirements: %s\n", req))
+	}
 
 	if len(detail.Inputs) > 0 {
 		output.WriteString("\n  Configuration inputs:\n")
-		for key, input := range detail.Inputs {
-			req := ""
+		for _, key := range sortedKeys(detail.Inputs) {
+			input := detail.Inputs[key]
+			var notes []string
+			if input.Type != "" {
+				notes = append(notes, input.Type)
+			}
 			if input.Required {
-				req = " (required)"
+				notes = append(notes, "required")
+			}
+			if input.Default != "" {
+				notes = append(notes, "default: "+input.Default)
+			} else if input.Type == "password" {
+				notes = append(notes, "generated if not set")
+			}
+			line := fmt.Sprintf("    --%s: %s", key, input.Label)
+			if len(notes) > 0 {
+				line += " (" + strings.Join(notes, ", ") + ")"
+			}
+			output.WriteString(line + "\n")
+			if input.Description != "" {
+				output.WriteString(fmt.Sprintf("        %s\n", input.Description))
 			}
-			output.WriteString(fmt.Sprintf("    --%s: %s%s\n", key, input.Label, req))
 		}
 	}
 
-	output.WriteString(fmt.Sprintf("\nDeploy locally: opensourcer deploy %s\n", software))
+	deployment, err := s.findDeployment(software)
+	if err != nil {
+		return "", err
+	}
+	if deployment == nil {
+		output.WriteString("\n  Deployed: no\n")
+		output.WriteString(fmt.Sprintf("\nDeploy locally: opensourcer deploy %s\n", software))
+		return output.String(), nil
+	}
+
+	output.WriteString(fmt.Sprintf("\n  Deployed: yes, %s (%s)\n", deployment.Status, deployment.Target))
+	if deployment.AWS != nil && deployment.AWS.PublicIP != "" && deployment.Port > 0 {
+		output.WriteString(fmt.Sprintf("  URL: http://%s:%d\n", deployment.AWS.PublicIP, deployment.Port))
+	} else if deployment.AWS == nil && deployment.Port > 0 {
+		output.WriteString(fmt.Sprintf("  URL: http://localhost:%d\n", deployment.Port))
+	}
+	output.WriteString(fmt.Sprintf("\nView logs: opensourcer logs %s\n", software))
 
 	return output.String(), nil
 }
 
+// writeServiceInfo describes a compose service: its catalog flags, image,
+// ports, volumes and resources
+func writeServiceInfo(output *strings.Builder, name string, svc ComposeService, info ServiceInfo, defaults map[string]string) {
+	var flags []string
+	if info.Exposed {
+		flags = append(flags, "exposed")
+	}
+	if info.Internal {
+		flags = append(flags, "internal")
+	}
+	if info.Stateless {
+		flags = append(flags, "stateless")
+	}
+	if info.ManagedOption != "" {
+		flags = append(flags, "managed: "+info.ManagedOption)
+	}
+	line := "    " + name
+	if len(flags) > 0 {
+		line += " [" + strings.Join(flags, ", ") + "]"
+	}
+	output.WriteString(line + "\n")
+
+	if svc.Image != "" {
+		image := interpolateCompose(svc.Image, defaults)
+		output.WriteString(fmt.Sprintf("      Image: %s, version %s\n", imageRepo(image), imageTag(image)))
+	} else {
+		output.WriteString("      Image: built from source\n")
+	}
+
+	var ports []string
+	for _, p := range svc.Ports {
+		container := p.containerPorts()
+		if p.Protocol != "" {
+			container += "/" + p.Protocol
+		}
+		if p.Unparsed != "" {
+			ports = append(ports, p.Unparsed)
+		} else if p.Host > 0 {
+			ports = append(ports, fmt.Sprintf("%s -> %s", p.hostPorts(), container))
+		} else if p.Variable {
+			ports = append(ports, fmt.Sprintf("%s (published on a port set at deploy time)", container))
+		} else {
+			ports = append(ports, fmt.Sprintf("%s (not published)", container))
+		}
+	}
+	if len(ports) > 0 {
+		output.WriteString(fmt.Sprintf("      Ports: %s\n", strings.Join(ports, ", ")))
+	}
+
+	var volumes []string
+	for _, v := range svc.Volumes {
+		volume := v.Target
+		if v.Source != "" {
+			volume = v.Source + " -> " + v.Target
+		}
+		if v.ReadOnly {
+			volume += " (read-only)"
+		}
+		volumes = append(volumes, volume)
+	}
+	if len(volumes) > 0 {
+		output.WriteString(fmt.Sprintf("      Volumes: %s\n", strings.Join(volumes, ", ")))
+	}
+
+	r := info.Resources
+	if limit := (resourceLimit{Memory: r.Memory, CPUs: formatCPUs(r.CPUs)}).String(); limit != "" {
+		output.WriteString(fmt.Sprintf("      Limits: %s\n", limit))
+	}
+	if minimum := (resourceLimit{Memory: r.MinMemory, CPUs: formatCPUs(r.MinCPUs)}).String(); minimum != "" {
+		output.WriteString(fmt.Sprintf("      Needs at least: %s\n", minimum))
+	}
+}
+
+// minimumResources sums the minimum memory and CPUs of all services
+func minimumResources(detail *CatalogDetail) string {
+	var memory int64
+	var cpus float64
+	for _, info := range detail.Services {
+		if m, err := parseMemory(info.Resources.MinMemory); err == nil {
+			memory += m
+		}
+		cpus += info.Resources.MinCPUs
+	}
+
+	var total resourceLimit
+	if memory > 0 {
+		total.Memory = formatMemory(memory)
+	}
+	total.CPUs = formatCPUs(cpus)
+	if s := total.String(); s != "" {
+		return "at least " + s
+	}
+	return ""
+}
+
 // Deploy deploys software locally or to cloud
 func (s *Service) Deploy(c *gofr.Context) (interface{}, error) {
 	software := getArg(c)
+
+	// A bundle carries its own catalog entry and images; --catalog-dir deploys
+	// an entry that is being written
+	var offline *bundle
+	path, catalogDir := getFlagValue(c, "from-bundle"), getFlagValue(c, "catalog-dir")
+	switch {
+	case path != "" && catalogDir != "":
+		return nil, fmt.Errorf("--from-bundle and --catalog-dir can't be combined")
+	case path != "":
+		var err error
+		if offline, err = s.openBundle(path, software); err != nil {
+			return nil, err
+		}
+		defer offline.Close()
+		s, software = offline.service, offline.manifest.Software
+	case catalogDir != "":
+		var err error
+		if s, software, err = s.withCatalogDir(catalogDir, software); err != nil {
+			return nil, err
+		}
+	}
+
 	if software == "" {
-		return nil, fmt.Errorf("usage: opensourcer deploy <software> [--target local|aws]")
+		return nil, fmt.Errorf("usage: opensourcer deploy <software> [--target local|aws] [--from-bundle <file>] [--catalog-dir <dir>] [--use-external <service>=<dsn>] [--memory [<service>=]<size>] [--cpus [<service>=]<n>] [--dry-run] [--keep-on-failure]")
 	}
 
 	detail, err := s.getCatalogDetail(software)
@@ -144,11 +421,16 @@ func (s *Service) Deploy(c *gofr.Context) (interface{}, error) {
 	}
 
 	// Parse inputs from flags
-	inputs := make(map[string]string)
-	for key := range detail.Inputs {
-		if val := c.Param(key); val != "" {
-			inputs[key] = val
-		}
+	inputs := parseInputs(c, detail)
+
+	externals, err := parseExternalServices(detail, getFlagValues("use-external"))
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := parseResourceOverrides()
+	if err != nil {
+		return nil, err
 	}
 
 	target := c.Param("target")
@@ -156,11 +438,31 @@ func (s *Service) Deploy(c *gofr.Context) (interface{}, error) {
 		target = "local"
 	}
 
+	if c.Param("dry-run") == "true" {
+		if target != "local" && target != "aws" {
+			return nil, fmt.Errorf("unknown target: %s", target)
+		}
+
+		plan, err := s.planDeployment(target, software, detail, inputs, externals, overrides)
+		if err != nil {
+			return nil, err
+		}
+		return s.showPlan(target, detail, plan)
+	}
+
 	switch target {
 	case "local":
-		return s.deployLocal(c, software, detail, inputs)
+		if offline != nil {
+			if err := offline.loadImages(); err != nil {
+				return nil, err
+			}
+		}
+		return s.deployLocal(c, software, detail, inputs, externals, overrides)
 	case "aws":
-		return nil, fmt.Errorf("AWS deployment not yet implemented. Use --target local")
+		if offline != nil {
+			return nil, fmt.Errorf("bundles can only be deployed locally")
+		}
+		return s.deployAWS(c, software, detail, inputs, externals, overrides)
 	default:
 		return nil, fmt.Errorf("unknown target: %s", target)
 	}
@@ -168,7 +470,12 @@ func (s *Service) Deploy(c *gofr.Context) (interface{}, error) {
 
 // List shows all deployments
 func (s *Service) List(c *gofr.Context) (interface{}, error) {
-	if len(s.deployments) == 0 {
+	deployments, err := s.readDeployments()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(deployments) == 0 {
 		return "\nNo deployments found.\n\nUse 'opensourcer deploy <software>' to create one.\n", nil
 	}
 
@@ -176,7 +483,7 @@ func (s *Service) List(c *gofr.Context) (interface{}, error) {
 	output.WriteString("\nYour Deployments\n")
 	output.WriteString(strings.Repeat("-", 70) + "\n\n")
 
-	for _, d := range s.deployments {
+	for _, d := range deployments {
 		statusIcon := "[running]"
 		if d.Status == "stopped" {
 			statusIcon = "[stopped]"
@@ -184,7 +491,12 @@ func (s *Service) List(c *gofr.Context) (interface{}, error) {
 
 		output.WriteString(fmt.Sprintf("  %s %-12s  %-10s %s\n", statusIcon, d.Software, d.Target, d.Status))
 		output.WriteString(fmt.Sprintf("     ID: %s\n", d.ID[:8]))
-		if d.Port > 0 {
+		if d.AWS != nil {
+			output.WriteString(fmt.Sprintf("     Instance: %s (%s)\n", d.AWS.InstanceID, d.AWS.Region))
+			if d.Port > 0 && d.AWS.PublicIP != "" {
+				output.WriteString(fmt.Sprintf("     URL: http://%s:%d\n", d.AWS.PublicIP, d.Port))
+			}
+		} else if d.Port > 0 {
 			output.WriteString(fmt.Sprintf("     URL: http://localhost:%d\n", d.Port))
 		}
 		output.WriteString(fmt.Sprintf("     Created: %s\n\n", d.CreatedAt.Format("2006-01-02 15:04")))
@@ -197,15 +509,30 @@ func (s *Service) List(c *gofr.Context) (interface{}, error) {
 func (s *Service) Logs(c *gofr.Context) (interface{}, error) {
 	software := getArg(c)
 	if software == "" {
-		return nil, fmt.Errorf("usage: opensourcer logs <software>")
+		return nil, fmt.Errorf("usage: opensourcer logs <software> [--follow] [--service <name>] [--since <time>] [--until <time>] [--tail <n>] [--timestamps] [--grep <pattern>]")
+	}
+
+	opts, err := parseLogOptions(c)
+	if err != nil {
+		return nil, err
 	}
 
-	deployment := s.findDeployment(software)
+	deployment, err := s.findDeployment(software)
+	if err != nil {
+		return nil, err
+	}
 	if deployment == nil {
 		return nil, fmt.Errorf("deployment '%s' not found", software)
 	}
 
-	return s.getDockerLogs(deployment)
+	if deployment.Target == "aws" {
+		if !opts.isDefault() {
+			return nil, fmt.Errorf("log filters are only supported for local deployments")
+		}
+		return s.getAWSLogs(deployment)
+	}
+
+	return s.streamDockerLogs(deployment, opts)
 }
 
 // Stop stops a running deployment
@@ -215,11 +542,18 @@ func (s *Service) Stop(c *gofr.Context) (interface{}, error) {
 		return nil, fmt.Errorf("usage: opensourcer stop <software>")
 	}
 
-	deployment := s.findDeployment(software)
+	deployment, err := s.findDeployment(software)
+	if err != nil {
+		return nil, err
+	}
 	if deployment == nil {
 		return nil, fmt.Errorf("deployment '%s' not found", software)
 	}
 
+	if deployment.Target == "aws" {
+		return s.stopAWS(deployment)
+	}
+
 	return s.stopDocker(deployment)
 }
 
@@ -227,36 +561,64 @@ func (s *Service) Stop(c *gofr.Context) (interface{}, error) {
 func (s *Service) Start(c *gofr.Context) (interface{}, error) {
 	software := getArg(c)
 	if software == "" {
-		return nil, fmt.Errorf("usage: opensourcer start <software>")
+		return nil, fmt.Errorf("usage: opensourcer start <software> [--refresh-lock]")
 	}
 
-	deployment := s.findDeployment(software)
+	deployment, err := s.findDeployment(software)
+	if err != nil {
+		return nil, err
+	}
 	if deployment == nil {
 		return nil, fmt.Errorf("deployment '%s' not found", software)
 	}
 
-	return s.startDocker(deployment)
+	refreshLock := c.Param("refresh-lock") == "true"
+	if deployment.Target == "aws" {
+		if refreshLock {
+			return nil, fmt.Errorf("image locks are only used by local deployments")
+		}
+		return s.startAWS(deployment)
+	}
+
+	return s.startDocker(deployment, refreshLock)
 }
 
 // Destroy removes a deployment completely
 func (s *Service) Destroy(c *gofr.Context) (interface{}, error) {
 	software := getArg(c)
 	if software == "" {
-		return nil, fmt.Errorf("usage: opensourcer destroy <software>")
+		return nil, fmt.Errorf("usage: opensourcer destroy <software> [--skip-hooks] [--remove-volumes]")
 	}
 
-	deployment := s.findDeployment(software)
+	deployment, err := s.findDeployment(software)
+	if err != nil {
+		return nil, err
+	}
 	if deployment == nil {
 		return nil, fmt.Errorf("deployment '%s' not found", software)
 	}
 
-	if _, err := s.destroyDocker(deployment); err != nil {
+	var output strings.Builder
+	destroy := func(d *LocalDeployment) (interface{}, error) {
+		return s.destroyDocker(d, c.Param("remove-volumes") == "true")
+	}
+	if deployment.Target == "aws" {
+		destroy = s.destroyAWS
+	} else if c.Param("skip-hooks") != "true" {
+		if err := s.runDeploymentHooks(&output, deployment, hookPreDestroy); err != nil {
+			return nil, fmt.Errorf("%w\n\nNothing was destroyed. Use --skip-hooks to destroy anyway", err)
+		}
+	}
+
+	if _, err := destroy(deployment); err != nil {
 		return nil, err
 	}
 
-	s.removeDeployment(deployment.ID)
+	if err := s.removeDeployment(deployment.ID); err != nil {
+		return nil, err
+	}
 
-	return fmt.Sprintf("\nDestroyed '%s' deployment\n", software), nil
+	return fmt.Sprintf("\n%sDestroyed '%s' deployment\n", output.String(), software), nil
 }
 
 // Helper functions
@@ -276,65 +638,18 @@ func (s *Service) getCatalogDetail(software string) (*CatalogDetail, error) {
 	return &detail, nil
 }
 
-func (s *Service) getComposePath(software string) string {
-	return filepath.Join(s.catalogPath, software, "docker-compose.yaml")
-}
-
-func (s *Service) loadDeployments() {
-	deployFile := filepath.Join(s.configPath, "deployments.json")
-	data, err := os.ReadFile(deployFile)
-	if err != nil {
-		return
-	}
-
-	var file DeploymentsFile
-	if err := json.Unmarshal(data, &file); err != nil {
-		return
-	}
-
-	s.deployments = file.Deployments
-}
-
-func (s *Service) saveDeployments() {
-	file := DeploymentsFile{Deployments: s.deployments}
-	data, _ := json.MarshalIndent(file, "", "  ")
-	deployFile := filepath.Join(s.configPath, "deployments.json")
-	_ = os.WriteFile(deployFile, data, 0644)
+func (s *Service) deploymentDir(software string) string {
+	return filepath.Join(s.configPath, "deployments", software)
 }
 
-func (s *Service) addDeployment(d LocalDeployment) {
-	s.deployments = append(s.deployments, d)
-	s.saveDeployments()
+// awsDeploymentDir is where an AWS deployment's files are staged, apart from
+// a local deployment of the same software
+func (s *Service) awsDeploymentDir(software string) string {
+	return filepath.Join(s.configPath, "aws-deployments", software)
 }
 
-func (s *Service) findDeployment(software string) *LocalDeployment {
-	for i := range s.deployments {
-		if s.deployments[i].Software == software {
-			return &s.deployments[i]
-		}
-	}
-	return nil
-}
-
-func (s *Service) updateDeploymentStatus(id, status string) {
-	for i := range s.deployments {
-		if s.deployments[i].ID == id {
-			s.deployments[i].Status = status
-			s.deployments[i].UpdatedAt = time.Now()
-			s.saveDeployments()
-			return
-		}
-	}
-}
-
-func (s *Service) removeDeployment(id string) {
-	for i := range s.deployments {
-		if s.deployments[i].ID == id {
-			s.deployments = append(s.deployments[:i], s.deployments[i+1:]...)
-			s.saveDeployments()
-			return
-		}
-	}
+func (s *Service) getComposePath(software string) string {
+	return filepath.Join(s.catalogPath, software, "docker-compose.yaml")
 }
 
 func generatePassword(length int) string {
@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteServiceInfo(t *testing.T) {
+	compose := parseTestCompose(t, `
+services:
+  web:
+    image: ghcr.io/org/app:${APP_VERSION:-1.4}
+    ports:
+      - "3000:3000"
+      - "8000-8001:9000-9001/udp"
+      - "${ADMIN_PORT}:8081"
+      - "9090"
+    volumes:
+      - data:/var/lib/app
+      - ./config.ini:/etc/app.ini:ro
+      - /tmp/cache
+  db:
+    image: postgres
+  builder:
+    build: .
+`)
+
+	tests := []struct {
+		name    string
+		service string
+		info    ServiceInfo
+		want    string
+	}{
+		{
+			name:    "exposed service",
+			service: "web",
+			info:    ServiceInfo{Exposed: true, Resources: ServiceResources{Memory: "1g", CPUs: 2, MinMemory: "512m", MinCPUs: 0.5}},
+			want: `    web [exposed]
+      Image: ghcr.io/org/app, version 1.4
+      Ports: 3000 -> 3000, 8000-8001 -> 9000-9001/udp, 8081 (published on a port set at deploy time), 9090 (not published)
+      Volumes: data -> /var/lib/app, ./config.ini -> /etc/app.ini (read-only), /tmp/cache
+      Limits: 1g memory, 2 CPUs
+      Needs at least: 512m memory, 0.5 CPUs
+`,
+		},
+		{
+			name:    "managed database",
+			service: "db",
+			info:    ServiceInfo{Internal: true, Stateless: true, ManagedOption: "postgres"},
+			want: `    db [internal, stateless, managed: postgres]
+      Image: postgres, version latest
+`,
+		},
+		{
+			name:    "built from source",
+			service: "builder",
+			want: `    builder
+      Image: built from source
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output strings.Builder
+			writeServiceInfo(&output, tt.service, compose.Services[tt.service], tt.info, map[string]string{})
+			if output.String() != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", output.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSoftwareInfo(t *testing.T) {
+	s := newTestService(t)
+	writeFile(t, filepath.Join(s.catalogPath, "app", "app.json"), `{
+  "name": "App",
+  "description": "An example app",
+  "website": "https://example.com",
+  "category": "Productivity",
+  "tags": ["notes", "wiki"],
+  "inputs": {
+    "version": {"label": "Version", "default": "2.0"},
+    "admin_password": {"label": "Admin password", "type": "password", "required": true, "description": "Used for the first login"}
+  },
+  "services": {
+    "web": {"exposed": true, "resources": {"min_memory": "512m", "min_cpus": 1}},
+    "db": {"resources": {"min_memory": "1g"}}
+  }
+}`)
+	writeFile(t, filepath.Join(s.catalogPath, "app", "docker-compose.yaml"), `
+services:
+  web:
+    image: org/app:${VERSION:-1.0}
+    ports:
+      - "3000:3000"
+  db:
+    image: postgres:16
+`)
+
+	tests := []struct {
+		name       string
+		deployment *LocalDeployment
+		want       []string
+	}{
+		{
+			name: "not deployed",
+			want: []string{
+				"  An example app\n",
+				"  Tags: notes, wiki\n",
+				"    web [exposed]\n      Image: org/app, version 2.0\n      Ports: 3000 -> 3000\n      Needs at least: 512m memory, 1 CPUs\n",
+				"    db\n      Image: postgres, version 16\n",
+				"  Requirements: at least 1.5GiB memory, 1 CPUs\n",
+				"    --admin_password: Admin password (password, required, generated if not set)\n        Used for the first login\n",
+				"    --version: Version (default: 2.0)\n",
+				"  Deployed: no\n",
+				"Deploy locally: opensourcer deploy app\n",
+			},
+		},
+		{
+			name:       "deployed locally",
+			deployment: &LocalDeployment{ID: "1", Software: "app", Target: "local", Status: "running", Port: 3000},
+			want:       []string{"  Deployed: yes, running (local)\n", "  URL: http://localhost:3000\n", "View logs: opensourcer logs app\n"},
+		},
+		{
+			name:       "deployed to AWS",
+			deployment: &LocalDeployment{ID: "1", Software: "app", Target: "aws", Status: "running", Port: 3000, AWS: &AWSResources{PublicIP: "203.0.113.10"}},
+			want:       []string{"  Deployed: yes, running (aws)\n", "  URL: http://203.0.113.10:3000\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.deployment != nil {
+				if err := s.addDeployment(*tt.deployment); err != nil {
+					t.Fatal(err)
+				}
+				defer func() {
+					if err := s.removeDeployment(tt.deployment.ID); err != nil {
+						t.Fatal(err)
+					}
+				}()
+			}
+
+			got, err := s.softwareInfo("app")
+			if err != nil {
+				t.Fatalf("softwareInfo: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("info does not contain %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+
+	if _, err := s.softwareInfo("missing"); err == nil || !strings.Contains(err.Error(), "not found in catalog") {
+		t.Errorf("error = %v, want the entry reported missing", err)
+	}
+}
@@ -0,0 +1,256 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	stateFileName = "deployments.json"
+
+	// stateVersion is the current deployments.json schema version. Files
+	// without a version field are version 0.
+	stateVersion = 1
+
+	// How long to wait for another opensourcer process to release the state lock
+	stateLockTimeout = 30 * time.Second
+)
+
+// stateMigrations upgrade a decoded deployments.json from version i to i+1
+var stateMigrations = []func(map[string]interface{}) error{
+	migrateStateV0,
+}
+
+// migrateStateV0 fills in fields that unversioned files may lack
+func migrateStateV0(state map[string]interface{}) error {
+	deployments, _ := state["deployments"].([]interface{})
+	for _, item := range deployments {
+		d, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("deployment record is not an object")
+		}
+		if target, _ := d["target"].(string); target == "" {
+			d["target"] = "local"
+		}
+		if d["inputs"] == nil {
+			d["inputs"] = map[string]interface{}{}
+		}
+	}
+	state["deployments"] = deployments
+	return nil
+}
+
+func (s *Service) statePath() string {
+	return filepath.Join(s.configPath, stateFileName)
+}
+
+// lockState takes an exclusive lock on a lock file next to deployments.json.
+// The OS releases it when the process exits, so a crashed process can't
+// leave a stale lock behind. The returned function releases the lock.
+func (s *Service) lockState() (func(), error) {
+	lockPath := s.statePath() + ".lock"
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", stateFileName, err)
+	}
+
+	deadline := time.Now().Add(stateLockTimeout)
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", stateFileName, err)
+		}
+		if locked {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			owner, _ := os.ReadFile(lockPath)
+			f.Close()
+			return nil, fmt.Errorf("timed out waiting for %s lock held by process %s", stateFileName, strings.TrimSpace(string(owner)))
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// Record the owner for the timeout message of other processes
+	if err := f.Truncate(0); err == nil {
+		_, _ = f.WriteAt([]byte(fmt.Sprintf("%d\n", os.Getpid())), 0)
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// readDeployments reads the deployment records from disk. Writes replace the
+// file atomically, so reading doesn't need the lock.
+func (s *Service) readDeployments() ([]LocalDeployment, error) {
+	data, err := os.ReadFile(s.statePath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", stateFileName, err)
+	}
+
+	file, err := decodeState(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w (a backup of the previous version may be at %s)",
+			s.statePath(), err, s.statePath()+".bak")
+	}
+
+	return file.Deployments, nil
+}
+
+// decodeState parses deployments.json, applying forward migrations to older versions
+func decodeState(data []byte) (*DeploymentsFile, error) {
+	var state map[string]interface{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	// A file holding just null decodes without error but has no records to trust
+	if state == nil {
+		return nil, fmt.Errorf("the file holds no deployment state")
+	}
+
+	version := 0
+	if v, ok := state["version"].(float64); ok {
+		version = int(v)
+	}
+	if version > stateVersion {
+		return nil, fmt.Errorf("state version %d was written by a newer opensourcer (this version supports %d)", version, stateVersion)
+	}
+
+	for ; version < stateVersion; version++ {
+		if err := stateMigrations[version](state); err != nil {
+			return nil, fmt.Errorf("migrating from version %d: %w", version, err)
+		}
+		state["version"] = version + 1
+	}
+
+	migrated, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+
+	var file DeploymentsFile
+	if err := json.Unmarshal(migrated, &file); err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+// writeDeployments backs up the current deployments.json, then writes the
+// records to a temporary file and renames it into place. Callers must hold
+// the state lock.
+func (s *Service) writeDeployments(deployments []LocalDeployment) error {
+	file := DeploymentsFile{Version: stateVersion, Deployments: deployments}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode deployments: %w", err)
+	}
+
+	if previous, err := os.ReadFile(s.statePath()); err == nil {
+		if err := os.WriteFile(s.statePath()+".bak", previous, 0644); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", stateFileName, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to back up %s: %w", stateFileName, err)
+	}
+
+	tmpPath := s.statePath() + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", stateFileName, err)
+	}
+	if err := os.Rename(tmpPath, s.statePath()); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write %s: %w", stateFileName, err)
+	}
+
+	return nil
+}
+
+// modifyDeployments re-reads the records under the state lock, applies fn and
+// writes the result, so concurrent invocations don't lose each other's changes
+func (s *Service) modifyDeployments(fn func([]LocalDeployment) ([]LocalDeployment, error)) error {
+	unlock, err := s.lockState()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	deployments, err := s.readDeployments()
+	if err != nil {
+		return err
+	}
+
+	deployments, err = fn(deployments)
+	if err != nil {
+		return err
+	}
+
+	return s.writeDeployments(deployments)
+}
+
+func (s *Service) addDeployment(d LocalDeployment) error {
+	return s.modifyDeployments(func(deployments []LocalDeployment) ([]LocalDeployment, error) {
+		for _, existing := range deployments {
+			if existing.ID == d.ID {
+				return nil, fmt.Errorf("deployment %s already exists", d.ID)
+			}
+		}
+		return append(deployments, d), nil
+	})
+}
+
+func (s *Service) findDeployment(software string) (*LocalDeployment, error) {
+	deployments, err := s.readDeployments()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range deployments {
+		if deployments[i].Software == software {
+			return &deployments[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// updateDeployment applies fn to the record with the given ID
+func (s *Service) updateDeployment(id string, fn func(*LocalDeployment)) error {
+	return s.modifyDeployments(func(deployments []LocalDeployment) ([]LocalDeployment, error) {
+		for i := range deployments {
+			if deployments[i].ID == id {
+				fn(&deployments[i])
+				deployments[i].UpdatedAt = time.Now()
+				return deployments, nil
+			}
+		}
+		return nil, fmt.Errorf("deployment %s no longer exists", id)
+	})
+}
+
+func (s *Service) updateDeploymentStatus(id, status string) error {
+	return s.updateDeployment(id, func(d *LocalDeployment) {
+		d.Status = status
+	})
+}
+
+func (s *Service) removeDeployment(id string) error {
+	return s.modifyDeployments(func(deployments []LocalDeployment) ([]LocalDeployment, error) {
+		for i := range deployments {
+			if deployments[i].ID == id {
+				return append(deployments[:i], deployments[i+1:]...), nil
+			}
+		}
+		return deployments, nil
+	})
+}
@@ -0,0 +1,232 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	dir := t.TempDir()
+	return &Service{configPath: dir, catalogPath: filepath.Join(dir, "catalog")}
+}
+
+func TestModifyDeploymentsConcurrent(t *testing.T) {
+	s := newTestService(t)
+
+	const writers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- s.addDeployment(LocalDeployment{ID: fmt.Sprint(i), Software: fmt.Sprintf("app-%d", i)})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("addDeployment: %v", err)
+		}
+	}
+
+	deployments, err := s.readDeployments()
+	if err != nil {
+		t.Fatalf("readDeployments: %v", err)
+	}
+	if len(deployments) != writers {
+		t.Errorf("got %d deployments, want %d", len(deployments), writers)
+	}
+}
+
+func TestLockStateExclusive(t *testing.T) {
+	s := newTestService(t)
+
+	unlock, err := s.lockState()
+	if err != nil {
+		t.Fatalf("lockState: %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		second, err := s.lockState()
+		if err != nil {
+			t.Errorf("second lockState: %v", err)
+			return
+		}
+		close(acquired)
+		second()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second lock acquired while the first was held")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	unlock()
+
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("second lock not acquired after the first was released")
+	}
+}
+
+func TestLockStateIgnoresLeftoverLockFile(t *testing.T) {
+	s := newTestService(t)
+
+	// A lock file left by a crashed process holds no flock
+	if err := os.WriteFile(s.statePath()+".lock", []byte("12345\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err := s.lockState()
+	if err != nil {
+		t.Fatalf("lockState: %v", err)
+	}
+	unlock()
+}
+
+func TestDecodeState(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantErr    bool
+		wantTarget string
+		wantInputs bool
+	}{
+		{
+			name:       "unversioned file gets defaults",
+			data:       `{"deployments":[{"id":"1","software":"gitea"}]}`,
+			wantTarget: "local",
+			wantInputs: true,
+		},
+		{
+			name:       "unversioned file keeps its target",
+			data:       `{"deployments":[{"id":"1","software":"gitea","target":"aws","inputs":{"domain":"x"}}]}`,
+			wantTarget: "aws",
+			wantInputs: true,
+		},
+		{
+			name:       "current version is read as is",
+			data:       `{"version":1,"deployments":[{"id":"1","software":"gitea","target":"local","inputs":{}}]}`,
+			wantTarget: "local",
+			wantInputs: true,
+		},
+		{
+			name:    "newer version is rejected",
+			data:    `{"version":99,"deployments":[]}`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed record is rejected",
+			data:    `{"deployments":["gitea"]}`,
+			wantErr: true,
+		},
+		{
+			name:    "null file is rejected",
+			data:    `null`,
+			wantErr: true,
+		},
+		{
+			name:    "array file is rejected",
+			data:    `[]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid JSON is rejected",
+			data:    `{"deployments":`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := decodeState([]byte(tt.data))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeState: %v", err)
+			}
+			if file.Version != stateVersion {
+				t.Errorf("version = %d, want %d", file.Version, stateVersion)
+			}
+			if len(file.Deployments) != 1 {
+				t.Fatalf("got %d deployments, want 1", len(file.Deployments))
+			}
+			d := file.Deployments[0]
+			if d.Target != tt.wantTarget {
+				t.Errorf("target = %q, want %q", d.Target, tt.wantTarget)
+			}
+			if tt.wantInputs && d.Inputs == nil {
+				t.Error("inputs is nil")
+			}
+		})
+	}
+}
+
+func TestDecodeStateWithoutRecords(t *testing.T) {
+	for _, data := range []string{`{}`, `{"deployments":null}`, `{"version":1,"deployments":[]}`} {
+		file, err := decodeState([]byte(data))
+		if err != nil {
+			t.Fatalf("decodeState(%s): %v", data, err)
+		}
+		if file.Version != stateVersion || len(file.Deployments) != 0 {
+			t.Errorf("decodeState(%s) = %+v, want an empty current-version state", data, file)
+		}
+	}
+}
+
+func TestReadDeploymentsNullFile(t *testing.T) {
+	s := newTestService(t)
+	writeFile(t, s.statePath(), "null")
+
+	if _, err := s.readDeployments(); err == nil || !strings.Contains(err.Error(), "no deployment state") {
+		t.Fatalf("error = %v, want a parse error", err)
+	}
+}
+
+func TestWriteDeploymentsBacksUp(t *testing.T) {
+	s := newTestService(t)
+
+	previous := []byte(`{"deployments":[{"id":"old","software":"gitea"}]}`)
+	if err := os.WriteFile(s.statePath(), previous, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.writeDeployments([]LocalDeployment{{ID: "new", Software: "ghost"}}); err != nil {
+		t.Fatalf("writeDeployments: %v", err)
+	}
+
+	backup, err := os.ReadFile(s.statePath() + ".bak")
+	if err != nil {
+		t.Fatalf("reading backup: %v", err)
+	}
+	if string(backup) != string(previous) {
+		t.Errorf("backup = %s, want the previous file", backup)
+	}
+
+	deployments, err := s.readDeployments()
+	if err != nil {
+		t.Fatalf("readDeployments: %v", err)
+	}
+	if len(deployments) != 1 || deployments[0].ID != "new" {
+		t.Errorf("got %+v, want the new record", deployments)
+	}
+	if _, err := os.Stat(s.statePath() + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temporary file was left behind")
+	}
+}
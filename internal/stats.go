@@ -0,0 +1,266 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"gofr.dev/pkg/gofr"
+)
+
+// deploymentUsage is the resource usage of one deployment's containers and volumes
+type deploymentUsage struct {
+	Containers int
+	Running    int
+	CPU        float64
+	Memory     int64
+	NetRx      int64
+	NetTx      int64
+	Disk       int64
+}
+
+// Stats shows CPU, memory, network and disk usage per deployment, once or
+// refreshing until interrupted with --watch
+func (s *Service) Stats(c *gofr.Context) (interface{}, error) {
+	software := getArg(c)
+
+	interval := 2 * time.Second
+	if value := getFlagValue(c, "interval"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid --interval %q, expected a duration like 5s", value)
+		}
+		interval = d
+	}
+
+	if err := checkDockerAvailable(); err != nil {
+		return nil, fmt.Errorf("docker is required for stats: %w", err)
+	}
+
+	if c.Param("watch") != "true" {
+		return s.statsTable(software)
+	}
+
+	for {
+		table, err := s.statsTable(software)
+		if err != nil {
+			return nil, err
+		}
+		// Clear the screen and move the cursor home before redrawing
+		fmt.Print("\033[H\033[2J")
+		fmt.Print(table)
+		fmt.Printf("\nRefreshing every %s, press Ctrl+C to exit\n", interval)
+		time.Sleep(interval)
+	}
+}
+
+func (s *Service) statsTable(software string) (string, error) {
+	deployments, err := s.readDeployments()
+	if err != nil {
+		return "", err
+	}
+
+	if software != "" {
+		var selected []LocalDeployment
+		for _, d := range deployments {
+			if d.Software == software {
+				selected = append(selected, d)
+			}
+		}
+		if len(selected) == 0 {
+			return "", fmt.Errorf("deployment '%s' not found", software)
+		}
+		deployments = selected
+	}
+
+	if len(deployments) == 0 {
+		return "\nNo deployments found.\n\nUse 'opensourcer deploy <software>' to create one.\n", nil
+	}
+
+	usage, err := collectUsage(deployments)
+	if err != nil {
+		return "", err
+	}
+
+	var output strings.Builder
+	output.WriteString("\n📊 Resource usage\n")
+	output.WriteString(strings.Repeat("-", 80) + "\n\n")
+	output.WriteString(fmt.Sprintf("  %-14s %-11s %-8s %-11s %-22s %s\n", "DEPLOYMENT", "CONTAINERS", "CPU", "MEMORY", "NET RX / TX", "DISK"))
+
+	var total deploymentUsage
+	for _, d := range deployments {
+		if d.Target == "aws" {
+			output.WriteString(fmt.Sprintf("  %-14s on AWS (%s), not measured\n", d.Software, d.AWS.InstanceID))
+			continue
+		}
+
+		u := usage[d.ID]
+		output.WriteString(fmt.Sprintf("  %-14s %-11s %-8s %-11s %-22s %s\n",
+			d.Software,
+			fmt.Sprintf("%d/%d", u.Running, u.Containers),
+			fmt.Sprintf("%.1f%%", u.CPU),
+			humanSize(u.Memory),
+			humanSize(u.NetRx)+" / "+humanSize(u.NetTx),
+			humanSize(u.Disk)))
+
+		total.Running += u.Running
+		total.Containers += u.Containers
+		total.CPU += u.CPU
+		total.Memory += u.Memory
+		total.NetRx += u.NetRx
+		total.NetTx += u.NetTx
+		total.Disk += u.Disk
+	}
+
+	output.WriteString(fmt.Sprintf("\n  %-14s %-11s %-8s %-11s %-22s %s\n",
+		"TOTAL",
+		fmt.Sprintf("%d/%d", total.Running, total.Containers),
+		fmt.Sprintf("%.1f%%", total.CPU),
+		humanSize(total.Memory),
+		humanSize(total.NetRx)+" / "+humanSize(total.NetTx),
+		humanSize(total.Disk)))
+	output.WriteString("\n  CPU is a percentage of one core. Disk is the size of the deployment's volumes.\n")
+
+	return output.String(), nil
+}
+
+// collectUsage gathers container stats and volume sizes for the local
+// deployments, keyed by deployment ID
+func collectUsage(deployments []LocalDeployment) (map[string]*deploymentUsage, error) {
+	usage := make(map[string]*deploymentUsage)
+	owner := make(map[string]*deploymentUsage)
+	byProject := make(map[string]*deploymentUsage)
+	var running []string
+
+	for _, d := range deployments {
+		if d.Target == "aws" {
+			continue
+		}
+		u := &deploymentUsage{}
+		usage[d.ID] = u
+		byProject[composeProjectName(d.Directory)] = u
+
+		out, err := composeCommand(d.Directory, "ps", "-a", "--format", "{{.ID}}\t{{.State}}").Output()
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			id, state, ok := strings.Cut(line, "\t")
+			if !ok {
+				continue
+			}
+			u.Containers++
+			if state == "running" {
+				u.Running++
+				running = append(running, id)
+				owner[id] = u
+			}
+		}
+	}
+
+	if len(running) > 0 {
+		args := append([]string{"stats", "--no-stream", "--format", "{{.Container}}\t{{.CPUPerc}}\t{{.MemUsage}}\t{{.NetIO}}"}, running...)
+		out, err := exec.Command("docker", args...).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get container stats: %w", err)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			fields := strings.Split(line, "\t")
+			if len(fields) != 4 || owner[fields[0]] == nil {
+				continue
+			}
+			u := owner[fields[0]]
+			cpu, _ := strconv.ParseFloat(strings.TrimSuffix(fields[1], "%"), 64)
+			u.CPU += cpu
+			mem, _, _ := strings.Cut(fields[2], "/")
+			u.Memory += parseSize(mem)
+			rx, tx, _ := strings.Cut(fields[3], "/")
+			u.NetRx += parseSize(rx)
+			u.NetTx += parseSize(tx)
+		}
+	}
+
+	sizes, err := volumeSizes()
+	if err != nil {
+		return nil, err
+	}
+	volumes, err := composeVolumes()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range volumes {
+		if u := byProject[v.project]; u != nil {
+			u.Disk += sizes[v.name]
+		}
+	}
+
+	return usage, nil
+}
+
+// volumeSizes returns the size of every Docker volume by name
+func volumeSizes() (map[string]int64, error) {
+	out, err := exec.Command("docker", "system", "df", "-v", "--format", "{{json .}}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get volume sizes: %w", err)
+	}
+
+	var df struct {
+		Volumes []struct {
+			Name string `json:"Name"`
+			Size string `json:"Size"`
+		} `json:"Volumes"`
+	}
+	if err := json.Unmarshal(out, &df); err != nil {
+		return nil, fmt.Errorf("failed to parse volume sizes: %w", err)
+	}
+
+	sizes := make(map[string]int64)
+	for _, v := range df.Volumes {
+		sizes[v.Name] = parseSize(v.Size)
+	}
+	return sizes, nil
+}
+
+// sizeUnits are the suffixes Docker prints, both decimal and binary
+var sizeUnits = []struct {
+	suffix string
+	bytes  float64
+}{
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+	{"kB", 1e3}, {"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+	{"B", 1},
+}
+
+// parseSize parses a human-readable size such as "1.5GiB" or "120kB" into bytes
+func parseSize(value string) int64 {
+	value = strings.TrimSpace(value)
+	for _, unit := range sizeUnits {
+		if number, ok := strings.CutSuffix(value, unit.suffix); ok {
+			n, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+			if err != nil {
+				return 0
+			}
+			return int64(n * unit.bytes)
+		}
+	}
+	n, _ := strconv.ParseFloat(value, 64)
+	return int64(n)
+}
+
+// humanSize formats bytes with decimal units, as Docker does
+func humanSize(bytes int64) string {
+	units := []string{"B", "kB", "MB", "GB", "TB"}
+	size := float64(bytes)
+	i := 0
+	for size >= 1000 && i < len(units)-1 {
+		size /= 1000
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	return fmt.Sprintf("%.1f%s", size, units[i])
+}
@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+	}{
+		{value: "0B", want: 0},
+		{value: "512B", want: 512},
+		{value: "120kB", want: 120000},
+		{value: "1.5MB", want: 1500000},
+		{value: "2GB", want: 2000000000},
+		{value: "64KiB", want: 64 << 10},
+		{value: "1.5GiB", want: 3 << 29},
+		{value: " 256MiB ", want: 256 << 20},
+		{value: "1TiB", want: 1 << 40},
+		{value: "4096", want: 4096},
+		{value: "--", want: 0},
+		{value: "lotsMB", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseSize(tt.value); got != tt.want {
+			t.Errorf("parseSize(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{bytes: 0, want: "0B"},
+		{bytes: 999, want: "999B"},
+		{bytes: 1000, want: "1.0kB"},
+		{bytes: 1500000, want: "1.5MB"},
+		{bytes: 2000000000, want: "2.0GB"},
+		{bytes: 3e15, want: "3000.0TB"},
+	}
+
+	for _, tt := range tests {
+		if got := humanSize(tt.bytes); got != tt.want {
+			t.Errorf("humanSize(%d) = %s, want %s", tt.bytes, got, tt.want)
+		}
+	}
+}
+
+func TestCollectUsage(t *testing.T) {
+	log := setupFakeDocker(t, "")
+	s := newTestService(t)
+
+	web := newDoctorDeployment(t, s, "web-1", "")
+	writeFile(t, filepath.Join(web, ".fake-ps"), "aaa\trunning\nbbb\trunning\nccc\texited\n")
+	idle := newDoctorDeployment(t, s, "idle-1", "")
+	writeFile(t, filepath.Join(idle, ".fake-ps"), "ddd\texited\n")
+
+	t.Setenv("FAKE_DOCKER_STATS", strings.Join([]string{
+		"aaa\t12.5%\t100MiB / 2GiB\t1.5MB / 500kB",
+		"bbb\t0.5%\t50MiB / 2GiB\t500kB / 500kB",
+		"zzz\t99%\t1GiB / 2GiB\t1GB / 1GB",
+	}, "\n")+"\n")
+	t.Setenv("FAKE_DOCKER_DF", `{"Volumes":[{"Name":"web-1_data","Size":"1.5GB"},{"Name":"web-1_config","Size":"500MB"},{"Name":"idle-1_data","Size":"10kB"},{"Name":"other_data","Size":"9GB"}]}`)
+	t.Setenv("FAKE_DOCKER_VOLUMES", "web-1_data\tweb-1\nweb-1_config\tweb-1\nidle-1_data\tidle-1\nother_data\tother\n")
+
+	usage, err := collectUsage([]LocalDeployment{
+		{ID: "1", Software: "web", Target: "local", Directory: web},
+		{ID: "2", Software: "idle", Target: "local", Directory: idle},
+		{ID: "3", Software: "cloud", Target: "aws"},
+	})
+	if err != nil {
+		t.Fatalf("collectUsage: %v", err)
+	}
+
+	tests := []struct {
+		id   string
+		want deploymentUsage
+	}{
+		{id: "1", want: deploymentUsage{Containers: 3, Running: 2, CPU: 13, Memory: 150 << 20, NetRx: 2000000, NetTx: 1000000, Disk: 2000000000}},
+		{id: "2", want: deploymentUsage{Containers: 1, Disk: 10000}},
+	}
+	for _, tt := range tests {
+		if got := usage[tt.id]; got == nil || *got != tt.want {
+			t.Errorf("usage of %s = %+v, want %+v", tt.id, got, tt.want)
+		}
+	}
+	if _, ok := usage["3"]; ok {
+		t.Error("AWS deployment measured")
+	}
+
+	// Only running containers are passed to docker stats
+	var stats []string
+	for _, call := range dockerCalls(t, log) {
+		if strings.HasPrefix(call, "stats ") {
+			stats = append(stats, call)
+		}
+	}
+	if len(stats) != 1 || !strings.HasSuffix(stats[0], " aaa bbb") {
+		t.Errorf("stats calls = %q, want one for the running containers", stats)
+	}
+}
@@ -12,6 +12,9 @@ type CatalogDetail struct {
 	Tags        []string                `json:"tags"`
 	Inputs      map[string]InputConfig  `json:"inputs"`
 	Services    map[string]ServiceInfo  `json:"services"`
+	Actions     map[string]ActionConfig `json:"actions"`
+	Hooks       map[string][]HookStep   `json:"hooks"`
+	Checks      []SmokeCheck            `json:"checks"`
 }
 
 // InputConfig represents a configurable input for software deployment
@@ -26,26 +29,82 @@ type InputConfig struct {
 
 // ServiceInfo represents information about a service component
 type ServiceInfo struct {
-	Exposed       bool   `json:"exposed"`
-	Stateless     bool   `json:"stateless"`
-	Internal      bool   `json:"internal"`
-	ManagedOption string `json:"managed_option"`
+	Exposed       bool             `json:"exposed"`
+	Stateless     bool             `json:"stateless"`
+	Internal      bool             `json:"internal"`
+	ManagedOption string           `json:"managed_option"`
+	Resources     ServiceResources `json:"resources"`
+}
+
+// ServiceResources represents the recommended limits and the minimum resources of a service
+type ServiceResources struct {
+	Memory    string  `json:"memory"`
+	CPUs      float64 `json:"cpus"`
+	MinMemory string  `json:"min_memory"`
+	MinCPUs   float64 `json:"min_cpus"`
+}
+
+// ActionConfig represents a management task that runs a command in one of the software's services
+type ActionConfig struct {
+	Description string                 `json:"description"`
+	Service     string                 `json:"service"`
+	Command     []string               `json:"command"`
+	Params      map[string]InputConfig `json:"params"`
+}
+
+// HookStep represents a command run in one of the software's services at a lifecycle stage
+type HookStep struct {
+	Service string   `json:"service"`
+	Command []string `json:"command"`
+}
+
+// SmokeCheck represents a check run by catalog test: an HTTP request to a path of the
+// exposed URL, or a command in one of the software's services that must exit successfully
+type SmokeCheck struct {
+	Name    string   `json:"name"`
+	Path    string   `json:"path"`
+	Status  int      `json:"status"`
+	Service string   `json:"service"`
+	Command []string `json:"command"`
 }
 
 // LocalDeployment represents a local Docker deployment
 type LocalDeployment struct {
-	ID        string            `json:"id"`
-	Software  string            `json:"software"`
-	Target    string            `json:"target"`
-	Status    string            `json:"status"`
-	Directory string            `json:"directory"`
-	Port      int               `json:"port"`
-	Inputs    map[string]string `json:"inputs"`
-	CreatedAt time.Time         `json:"created_at"`
-	UpdatedAt time.Time         `json:"updated_at"`
+	ID               string                 `json:"id"`
+	Software         string                 `json:"software"`
+	Target           string                 `json:"target"`
+	Status           string                 `json:"status"`
+	Directory        string                 `json:"directory"`
+	Port             int                    `json:"port"`
+	Inputs           map[string]string      `json:"inputs"`
+	ExternalServices []string               `json:"external_services,omitempty"`
+	AWS              *AWSResources          `json:"aws,omitempty"`
+	Adopted          bool                   `json:"adopted,omitempty"`
+	Images           map[string]LockedImage `json:"images,omitempty"`
+	CreatedAt        time.Time              `json:"created_at"`
+	UpdatedAt        time.Time              `json:"updated_at"`
+}
+
+// LockedImage records the digest a service's image was pinned to and the image it was resolved from.
+// Images loaded from a bundle have no registry digest locally and are pinned by ID instead.
+type LockedImage struct {
+	Image  string `json:"image"`
+	Digest string `json:"digest"`
+	ID     string `json:"id,omitempty"`
+}
+
+// AWSResources records the cloud resources backing an AWS deployment
+type AWSResources struct {
+	Region          string `json:"region"`
+	Endpoint        string `json:"endpoint,omitempty"`
+	InstanceID      string `json:"instance_id"`
+	VolumeID        string `json:"volume_id"`
+	SecurityGroupID string `json:"security_group_id"`
+	PublicIP        string `json:"public_ip,omitempty"`
 }
 
 // DeploymentsFile represents the structure of the deployments.json file
 type DeploymentsFile struct {
+	Version     int               `json:"version"`
 	Deployments []LocalDeployment `json:"deployments"`
 }
